@@ -6,7 +6,9 @@ require (
 	fyne.io/fyne/v2 v2.5.4
 	github.com/davecgh/go-spew v1.1.1
 	github.com/empack/minuit2go v0.0.0-20250212104857-a1740a8eb28b
+	github.com/stretchr/testify v1.10.0
 	golang.org/x/image v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -31,11 +33,9 @@ require (
 	github.com/rymdport/portal v0.4.0 // indirect
 	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c // indirect
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/yuin/goldmark v1.7.8 // indirect
 	golang.org/x/mobile v0.0.0-20250210185054-b38b8813d607 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
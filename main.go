@@ -1,14 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"physicsGUI/pkg/gui"
 	"physicsGUI/pkg/trigger"
 )
 
 func main() {
+	modelFile := flag.String("model", "", "model definition file (.yaml or .json), the built-in model is used if empty")
+	flag.Parse()
+
 	fmt.Println("Hello, World!")
 
+	// load the model definition before the gui is built
+	if *modelFile != "" {
+		if err := gui.LoadModelDefinition(*modelFile); err != nil {
+			log.Fatal("error while loading model definition: ", err)
+		}
+	}
+
 	// Initialize trigger for recalculating gui based on changes
 	trigger.Init()
 
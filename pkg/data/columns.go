@@ -0,0 +1,173 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"physicsGUI/pkg/function"
+	"strconv"
+	"time"
+)
+
+// ColumnUnit is the meaning of the first column (and the resolution column) of an imported file
+type ColumnUnit string
+
+const (
+	// momentum transfer in 1/Å (no conversion)
+	QZ_ANGSTROM = ColumnUnit("qz (1/Å)")
+	// momentum transfer in 1/nm
+	QZ_NANOMETER = ColumnUnit("qz (1/nm)")
+	// angle of incidence in degree, needs the wavelength
+	THETA = ColumnUnit("θ (deg)")
+	// scattering angle in degree, needs the wavelength
+	TWO_THETA = ColumnUnit("2θ (deg)")
+	// wavelength in Å (time of flight), needs the angle of incidence
+	WAVELENGTH = ColumnUnit("λ (Å)")
+)
+
+// ColumnUnitNames returns the names of all column units
+func ColumnUnitNames() []string {
+	return []string{string(QZ_ANGSTROM), string(QZ_NANOMETER), string(THETA), string(TWO_THETA), string(WAVELENGTH)}
+}
+
+// metadata keys of converted datasets
+const (
+	METADATA_COLUMN     = "column"
+	METADATA_WAVELENGTH = "wavelength"
+	METADATA_ANGLE      = "angle"
+)
+
+// Columns declares the semantics of the columns of an imported file
+type Columns struct {
+	// unit of the first and the resolution column
+	X ColumnUnit
+	// wavelength in Å, converts angle columns
+	Wavelength float64
+	// angle of incidence in degree, converts wavelength columns
+	Angle float64
+}
+
+// Dataset is an imported measurement with the original units as metadata
+type Dataset struct {
+	Points   function.Points
+	Metadata map[string]string
+}
+
+// NewMetadata returns the metadata of a data track of the dataset imported from the file at source
+// sample, instrument, temperature (K) and units get their fields, all other metadata become free-form values
+func (d *Dataset) NewMetadata(source string) *function.Metadata {
+	metadata := &function.Metadata{
+		Name:       filepath.Base(source),
+		Source:     source,
+		ImportTime: time.Now(),
+	}
+	if dataset, ok := d.Metadata[METADATA_DATASET]; ok {
+		metadata.Name += " " + dataset
+	}
+
+	for key, value := range d.Metadata {
+		switch key {
+		case METADATA_SAMPLE:
+			metadata.Sample = value
+		case METADATA_INSTRUMENT:
+			metadata.Instrument = value
+		case METADATA_UNITS:
+			metadata.Units = value
+		case METADATA_TEMPERATURE:
+			if temperature, err := strconv.ParseFloat(value, 64); err == nil {
+				metadata.Temperature = temperature
+				continue
+			}
+			metadata.Set(key, value)
+		default:
+			metadata.Set(key, value)
+		}
+	}
+	// files without units have the declared import columns
+	if column, ok := d.Metadata[METADATA_COLUMN]; ok && metadata.Units == "" {
+		metadata.Units = column
+	}
+
+	return metadata
+}
+
+// NeedsWavelength returns true if the conversion of the columns uses the wavelength
+func (c Columns) NeedsWavelength() bool {
+	return c.X == THETA || c.X == TWO_THETA
+}
+
+// NeedsAngle returns true if the conversion of the columns uses the angle of incidence
+func (c Columns) NeedsAngle() bool {
+	return c.X == WAVELENGTH
+}
+
+// Convert returns copies of the points with the first column converted to qz in 1/Å, sorted by qz
+// the resolution column is propagated linearly (dq = |dq/dx| dx), the signal and its error are not changed
+func (c Columns) Convert(points function.Points) (*Dataset, error) {
+	unit := c.X
+	if unit == "" {
+		unit = QZ_ANGSTROM
+	}
+
+	metadata := map[string]string{METADATA_COLUMN: string(unit)}
+	if c.NeedsWavelength() {
+		if !(c.Wavelength > 0) {
+			return nil, fmt.Errorf("conversion error: column '%s' needs a positive wavelength, got %g", unit, c.Wavelength)
+		}
+		metadata[METADATA_WAVELENGTH] = strconv.FormatFloat(c.Wavelength, 'g', -1, 64)
+	}
+	if c.NeedsAngle() {
+		if !(c.Angle > 0 && c.Angle < 90) {
+			return nil, fmt.Errorf("conversion error: column '%s' needs an angle between 0 and 90 degree, got %g", unit, c.Angle)
+		}
+		metadata[METADATA_ANGLE] = strconv.FormatFloat(c.Angle, 'g', -1, 64)
+	}
+
+	converted := make(function.Points, len(points))
+	for i, p := range points {
+		q, dq, err := c.convert(unit, p.X, p.Resolution)
+		if err != nil {
+			return nil, fmt.Errorf("conversion error in point %d: %v", i+1, err)
+		}
+		converted[i] = &function.Point{
+			X:          q,
+			Y:          p.Y,
+			Error:      p.Error,
+			Resolution: dq,
+		}
+	}
+	// wavelength columns are descending in qz
+	converted.Sort()
+
+	return &Dataset{Points: converted, Metadata: metadata}, nil
+}
+
+// converts a value x and its resolution dx of the unit into qz and dqz in 1/Å
+func (c Columns) convert(unit ColumnUnit, x, dx float64) (float64, float64, error) {
+	toRadian := math.Pi / 180
+
+	switch unit {
+	case QZ_ANGSTROM:
+		return x, dx, nil
+	case QZ_NANOMETER:
+		return x / 10, dx / 10, nil
+	case THETA, TWO_THETA:
+		theta, dtheta := x*toRadian, dx*toRadian
+		if unit == TWO_THETA {
+			theta, dtheta = theta/2, dtheta/2
+		}
+		if theta < 0 || theta > math.Pi/2 {
+			return 0, 0, fmt.Errorf("angle %g out of range", x)
+		}
+		k := 4 * math.Pi / c.Wavelength
+		return k * math.Sin(theta), k * math.Cos(theta) * dtheta, nil
+	case WAVELENGTH:
+		if !(x > 0) {
+			return 0, 0, fmt.Errorf("wavelength %g is not positive", x)
+		}
+		sinTheta := math.Sin(c.Angle * toRadian)
+		return 4 * math.Pi * sinTheta / x, 4 * math.Pi * sinTheta * dx / (x * x), nil
+	default:
+		return 0, 0, fmt.Errorf("unknown column unit '%s'", unit)
+	}
+}
@@ -0,0 +1,118 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Format is a file format of measured data, formats are selected by their content (Sniff) or the file extension
+type Format struct {
+	Name string
+	// file extensions including the dot (e.g. ".dat"), compared case insensitive
+	Extensions []string
+	// optional, reports whether the content is in this format, it takes precedence over the extension
+	Sniff func(data []byte) bool
+	// the file has no units, the first column is declared by the import columns (see Columns)
+	UsesColumns bool
+	// parses the file into one or more datasets with qz in 1/Å
+	Import func(data []byte, options ImportOptions) ([]*Dataset, error)
+}
+
+// ImportOptions are the options of the user for formats without units
+type ImportOptions struct {
+	Parse   ParseOptions
+	Columns Columns
+}
+
+// column data (see ParseWith), used for files no other format claims
+var columnFormat = &Format{
+	Name:        "Columns",
+	Extensions:  []string{".dat", ".txt", ".csv", ".tsv"},
+	UsesColumns: true,
+	Import: func(data []byte, options ImportOptions) ([]*Dataset, error) {
+		points, err := ParseWith(data, options.Parse)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			return nil, errors.New("no data")
+		}
+		dataset, err := options.Columns.Convert(points)
+		if err != nil {
+			return nil, err
+		}
+		return []*Dataset{dataset}, nil
+	},
+}
+
+// ORSO reflectivity text files (see ParseORSO)
+var orsoFormat = &Format{
+	Name:       "ORSO",
+	Extensions: []string{".ort"},
+	Sniff:      IsORSO,
+	Import: func(data []byte, _ ImportOptions) ([]*Dataset, error) {
+		return ParseORSO(data)
+	},
+}
+
+// registered formats in the order they are tried, the column format is the fallback
+var formats = []*Format{orsoFormat, columnFormat}
+
+// RegisterFormat adds a data format, the names of the formats are unique
+func RegisterFormat(format *Format) error {
+	if format == nil || format.Name == "" || format.Import == nil {
+		return errors.New("data format needs a name and an import function")
+	}
+	if _, err := GetFormat(format.Name); err == nil {
+		return fmt.Errorf("data format '%s' already registered", format.Name)
+	}
+	formats = append(formats, format)
+	return nil
+}
+
+// FormatNames returns the names of all registered data formats
+func FormatNames() []string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = format.Name
+	}
+	return names
+}
+
+// GetFormat returns the data format with the given name
+func GetFormat(name string) (*Format, error) {
+	for _, format := range formats {
+		if format.Name == name {
+			return format, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown data format '%s'", name)
+}
+
+// FindFormat returns the format of a file, the first format recognizing the content,
+// otherwise the first format with the extension of the filename and the column format if none matches
+func FindFormat(filename string, data []byte) *Format {
+	for _, format := range formats {
+		if format.Sniff != nil && format.Sniff(data) {
+			return format
+		}
+	}
+	if format := formatByExtension(filename); format != nil {
+		return format
+	}
+	return columnFormat
+}
+
+// returns the first format with the extension of the filename or nil
+func formatByExtension(filename string) *Format {
+	extension := filepath.Ext(filename)
+	for _, format := range formats {
+		if slices.ContainsFunc(format.Extensions, func(e string) bool { return strings.EqualFold(e, extension) }) {
+			return format
+		}
+	}
+	return nil
+}
@@ -2,81 +2,194 @@ package data
 
 import (
 	"fmt"
+	"math"
 	"physicsGUI/pkg/function"
-	"regexp"
 	"strconv"
 	"strings"
 )
 
-var (
-	// floatPattern is a regex pattern that matches a float number
-	// it accepts scientific notation and normal floats
-	// f.e. 1.0, 1.0e-10, 1.0e+10
-	floatPattern = `(\d+\.\d+(?:[eE][+-]\d+)?)`
+// Delimiter separates the columns of a data file
+type Delimiter string
 
-	// re is a regex pattern that matches a line with 3 floats
-	// separated by whitespace
-	// f.e. 1.0 2.0 3.0, 1.0e-10 2.0e-10 3.0e-10
-	re = regexp.MustCompile(floatPattern + `\s+` + floatPattern + `\s+` + floatPattern)
+const (
+	// detects the delimiter from the first data line (semicolon, comma, otherwise whitespace)
+	AUTO_DELIMITER = Delimiter("Auto")
+	// any sequence of spaces and tabs
+	WHITESPACE_DELIMITER = Delimiter("Whitespace")
+	COMMA_DELIMITER      = Delimiter("Comma")
+	// a single tab, empty fields are an error
+	TAB_DELIMITER       = Delimiter("Tab")
+	SEMICOLON_DELIMITER = Delimiter("Semicolon")
 )
 
+// DelimiterNames returns the names of all delimiters
+func DelimiterNames() []string {
+	return []string{string(AUTO_DELIMITER), string(WHITESPACE_DELIMITER), string(COMMA_DELIMITER), string(TAB_DELIMITER), string(SEMICOLON_DELIMITER)}
+}
+
+// ParseOptions configures the column data parser
+type ParseOptions struct {
+	Delimiter Delimiter
+	// lines starting with one of the prefixes are skipped
+	CommentPrefixes []string
+	// relative error (error = RelativeError * |signal|) of files with only two columns
+	RelativeError float64
+}
+
+// DefaultParseOptions returns the options used by Parse
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		Delimiter:       AUTO_DELIMITER,
+		CommentPrefixes: []string{"#", "%"},
+		RelativeError:   0.05,
+	}
+}
+
+// Parse parses column data (q, signal, error and the optional resolution) with the default options
 func Parse(data []byte) (function.Points, error) {
-	lines := strings.Split(string(data), "\n")
+	return ParseWith(data, DefaultParseOptions())
+}
+
+// ParseWith parses column data with the options, the columns are q, signal, the optional error and the optional resolution
+// further columns are ignored. Comment lines and header lines (lines without any number before the first data line,
+// also after the count line) are skipped, a single integer in the first data line is the number of points
+// and checked against the parsed points
+func ParseWith(data []byte, options ParseOptions) (function.Points, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
 
 	measurements := make(function.Points, 0)
 	expectedLength := -1
+	delimiter := options.Delimiter
 
 	for i, v := range lines {
-		if i == 0 {
-			v = strings.TrimSpace(v)
-			lengthString, err := strconv.Atoi(v)
-			if err != nil {
-				return nil, fmt.Errorf("parse error: expected int in first line: %v", err)
-			}
-
-			expectedLength = lengthString
-			continue
-		}
+		lineNumber := i + 1
+		v = strings.TrimSpace(v)
 
-		// skip empty lines (possible at the end)
-		if v == "" {
+		// skip empty lines (possible at the end) and comments
+		if v == "" || isComment(v, options.CommentPrefixes) {
 			continue
 		}
 
-		matches := re.FindStringSubmatch(v)
-		if len(matches) != 4 {
-			return nil, fmt.Errorf("parse error: expected '<FLOAT> <FLOAT> <FLOAT>' got '%s'", v)
+		if delimiter == "" || delimiter == AUTO_DELIMITER {
+			delimiter = detectDelimiter(v)
 		}
+		fields := splitFields(v, delimiter)
 
-		// parse qz value
-		qz, err := strconv.ParseFloat(matches[1], 64)
+		values, err := parseFloats(fields)
 		if err != nil {
-			return nil, fmt.Errorf("parse error: expected float in first column: %v", err)
+			// text before the first data line is a header
+			if len(measurements) == 0 && isHeader(fields) {
+				delimiter = options.Delimiter
+				continue
+			}
+			return nil, fmt.Errorf("parse error in line %d: %v", lineNumber, err)
 		}
 
-		// parse data/signal value
-		data, err := strconv.ParseFloat(matches[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf("parse error: expected float between first and last column: %v", err)
+		// optional count line
+		if len(values) == 1 && len(measurements) == 0 && expectedLength < 0 {
+			count, err := strconv.Atoi(fields[0])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("parse error in line %d: expected the number of points, got '%s'", lineNumber, fields[0])
+			}
+			expectedLength = count
+			// the count line does not tell the delimiter of the data
+			delimiter = options.Delimiter
+			continue
 		}
 
-		// parse error value
-		ev, err := strconv.ParseFloat(matches[3], 64)
-		if err != nil {
-			return nil, fmt.Errorf("parse error: expected float in last column: %v", err)
+		if len(values) < 2 {
+			return nil, fmt.Errorf("parse error in line %d: expected at least 2 columns (q, signal) got %d", lineNumber, len(values))
 		}
 
-		// ? maybe change whole measurement to point already?
-		measurements = append(measurements, &function.Point{
-			X:     qz,
-			Y:     data,
-			Error: ev,
-		})
+		point := &function.Point{
+			X: values[0],
+			Y: values[1],
+		}
+		if len(values) > 2 {
+			point.Error = values[2]
+		} else {
+			// estimate the error of files without error column
+			point.Error = options.RelativeError * math.Abs(point.Y)
+		}
+		if len(values) > 3 {
+			point.Resolution = values[3]
+		}
+		measurements = append(measurements, point)
 	}
 
-	if expectedLength != len(measurements) {
+	if expectedLength >= 0 && expectedLength != len(measurements) {
 		return nil, fmt.Errorf("parse error: expected length (%d) does not match actual length (%d)", expectedLength, len(measurements))
 	}
 
 	return measurements, nil
 }
+
+// returns true if the line starts with one of the comment prefixes
+func isComment(line string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns true if a line is a header (column names), none of its fields is a number
+// so data lines with a single invalid field are reported as errors instead of being skipped
+func isHeader(fields []string) bool {
+	for _, field := range fields {
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// detects the delimiter of a data line
+func detectDelimiter(line string) Delimiter {
+	switch {
+	case strings.Contains(line, ";"):
+		return SEMICOLON_DELIMITER
+	case strings.Contains(line, ","):
+		return COMMA_DELIMITER
+	default:
+		return WHITESPACE_DELIMITER
+	}
+}
+
+// splits a line into its trimmed fields
+func splitFields(line string, delimiter Delimiter) []string {
+	var fields []string
+	switch delimiter {
+	case COMMA_DELIMITER:
+		fields = strings.Split(line, ",")
+	case TAB_DELIMITER:
+		fields = strings.Split(line, "\t")
+	case SEMICOLON_DELIMITER:
+		fields = strings.Split(line, ";")
+	default:
+		return strings.Fields(line)
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	// trailing delimiter
+	if len(fields) > 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	return fields
+}
+
+// parses all fields as floats
+func parseFloats(fields []string) ([]float64, error) {
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected float in column %d got '%s'", i+1, field)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
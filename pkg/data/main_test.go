@@ -1,8 +1,11 @@
 package data
 
 import (
+	"math"
 	"os"
 	"path"
+	"physicsGUI/pkg/function"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -39,3 +42,258 @@ func TestImportAlt(t *testing.T) {
 
 	spew.Dump(data)
 }
+
+func TestImportResolutionColumn(t *testing.T) {
+	data, err := Parse([]byte("2\n0.01 1.0 0.1 0.0005\n0.02 0.5 0.05\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data[0].Resolution != 0.0005 || data[1].Resolution != 0 {
+		t.Errorf("expected resolutions 0.0005 and 0 got %g and %g", data[0].Resolution, data[1].Resolution)
+	}
+}
+
+func TestConvertColumns(t *testing.T) {
+	wavelength := 1.5406
+	q := 4 * math.Pi * math.Sin(0.5*math.Pi/180) / wavelength
+	dq := 4 * math.Pi * math.Cos(0.5*math.Pi/180) * (0.01 * math.Pi / 180) / wavelength
+
+	tests := []struct {
+		columns Columns
+		x, dx   float64
+	}{
+		{Columns{X: QZ_ANGSTROM}, q, dq},
+		{Columns{X: QZ_NANOMETER}, 10 * q, 10 * dq},
+		{Columns{X: THETA, Wavelength: wavelength}, 0.5, 0.01},
+		{Columns{X: TWO_THETA, Wavelength: wavelength}, 1, 0.02},
+		{Columns{X: WAVELENGTH, Angle: 0.5}, wavelength, wavelength * dq / q},
+	}
+
+	for _, test := range tests {
+		dataset, err := test.columns.Convert(function.Points{{X: test.x, Y: 0.5, Error: 0.05, Resolution: test.dx}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := dataset.Points[0]
+		if math.Abs(p.X-q) > 1e-12 || math.Abs(p.Resolution-dq) > 1e-12 || p.Y != 0.5 || p.Error != 0.05 {
+			t.Errorf("%s: expected q=%g dq=%g got q=%g dq=%g", test.columns.X, q, dq, p.X, p.Resolution)
+		}
+		if dataset.Metadata[METADATA_COLUMN] != string(test.columns.X) {
+			t.Errorf("%s: expected column metadata got '%s'", test.columns.X, dataset.Metadata[METADATA_COLUMN])
+		}
+	}
+
+	if _, err := (Columns{X: THETA}).Convert(function.Points{{X: 0.5}}); err == nil {
+		t.Error("expected an error without wavelength")
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options ParseOptions
+	}{
+		{"comments and header", "# sample\n% instrument\nq R dR\n1 -0.5 0.1\n2e-1 2 0.2\n", DefaultParseOptions()},
+		{"comma", "q,R,dR,dQ\n1,-0.5,0.1,0.01\n0.2,2,0.2,0.01\n", DefaultParseOptions()},
+		{"semicolon", "2\n1;-0.5;0.1\n0.2;2;0.2;\n", DefaultParseOptions()},
+		{"header after count", "2\nq R dR\n1 -0.5 0.1\n0.2 2 0.2\n", DefaultParseOptions()},
+		{"tab", "1\t-0.5\t0.1\t0.01\t7\n0.2\t2\t0.2\t0.01\t7\n", ParseOptions{Delimiter: TAB_DELIMITER}},
+	}
+
+	for _, test := range tests {
+		data, err := ParseWith([]byte(test.content), test.options)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(data) != 2 || data[0].X != 1 || data[0].Y != -0.5 || data[0].Error != 0.1 || data[1].X != 0.2 {
+			t.Errorf("%s: unexpected points %v %v", test.name, *data[0], *data[len(data)-1])
+		}
+	}
+}
+
+func TestParseEstimatedError(t *testing.T) {
+	data, err := Parse([]byte("0.01 2\n0.02 -1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0].Error != 0.1 || data[1].Error != 0.05 {
+		t.Errorf("expected estimated errors 0.1 and 0.05 got %g and %g", data[0].Error, data[1].Error)
+	}
+}
+
+func TestParseErrorLine(t *testing.T) {
+	_, err := Parse([]byte("# comment\n0.01 1 0.1\n0.02 x 0.1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error in line 3 got %v", err)
+	}
+
+	// a first data line with an invalid field is no header
+	_, err = Parse([]byte("0.1 1 abc\n0.2 1 0.1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected an error in line 1 got %v", err)
+	}
+
+	_, err = Parse([]byte("3\n0.01 1 0.1\n0.02 1 0.1\n"))
+	if err == nil {
+		t.Error("expected a length mismatch error")
+	}
+}
+
+func TestParseORSO(t *testing.T) {
+	content := `# # ORSO reflectivity data file | 1.0 standard | YAML encoding | https://www.reflectometry.org/
+# data_source:
+#   owner: {name: someone}
+#   experiment: {instrument: amor, probe: neutron}
+#   sample: {name: Ni on Si}
+# data_set: spin_up
+# columns:
+# - {name: Qz, unit: 1/nm}
+# - {name: R}
+# - {error_of: R, error_type: uncertainty, value_is: sigma}
+# - {error_of: Qz, error_type: resolution, value_is: FWHM}
+# # Qz (1/nm)    R    sR    sQz
+0.2 0.5 0.05 0.0235482
+0.1 1.0 0.1 0.0235482
+# data_set: spin_down
+0.3 0.25 0.025 0.0235482
+`
+	datasets, err := ParseORSO([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasets) != 2 || len(datasets[0].Points) != 2 || len(datasets[1].Points) != 1 {
+		t.Fatalf("expected datasets with 2 and 1 points got %d datasets", len(datasets))
+	}
+
+	p := datasets[0].Points[0]
+	if math.Abs(p.X-0.01) > 1e-12 || p.Y != 1 || math.Abs(p.Resolution-0.001) > 1e-6 {
+		t.Errorf("expected the sorted point q=0.01 R=1 dq=0.001 got %v", *p)
+	}
+	if datasets[0].Metadata[METADATA_DATASET] != "spin_up" || datasets[1].Metadata[METADATA_DATASET] != "spin_down" {
+		t.Errorf("expected datasets spin_up and spin_down got %v", datasets[1].Metadata)
+	}
+	if datasets[1].Metadata[METADATA_SAMPLE] != "Ni on Si" || datasets[1].Metadata[METADATA_INSTRUMENT] != "amor" {
+		t.Errorf("expected the header of the first dataset got %v", datasets[1].Metadata)
+	}
+
+	if _, err := ParseORSO([]byte("# data_set: 0\n0.1 1\n")); err == nil {
+		t.Error("expected an error without orso line")
+	}
+}
+
+func TestParseORSOColumns(t *testing.T) {
+	content := `# # ORSO reflectivity data file | 1.0 standard | YAML encoding | https://www.reflectometry.org/
+# data_set: 0
+# columns:
+# - {name: Qz, unit: 1/angstrom}
+# - {name: R}
+# - {error_of: Qz, error_type: resolution, value_is: sigma}
+0.1 1.0 0.001
+`
+	if _, err := ParseORSO([]byte(content)); err == nil || !strings.Contains(err.Error(), "column 3") {
+		t.Errorf("expected an error of column 3 got %v", err)
+	}
+}
+
+func TestFindFormat(t *testing.T) {
+	orso := []byte(ORSO_MAGIC + " | 1.0 standard\n")
+	tests := []struct {
+		filename string
+		content  []byte
+		expected string
+	}{
+		{"data.ort", []byte("0.1 1 0.1\n"), "ORSO"},
+		{"data.dat", orso, "ORSO"},
+		{"data.dat", []byte("0.1 1 0.1\n"), "Columns"},
+		{"data.unknown", []byte("0.1 1 0.1\n"), "Columns"},
+	}
+	for _, test := range tests {
+		if format := FindFormat(test.filename, test.content); format.Name != test.expected {
+			t.Errorf("%s: expected format %s got %s", test.filename, test.expected, format.Name)
+		}
+	}
+
+	custom := &Format{Name: "Custom", Extensions: []string{".CUS"}, Import: columnFormat.Import}
+	if err := RegisterFormat(custom); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { formats = formats[:len(formats)-1] }()
+	if format := FindFormat("data.cus", nil); format != custom {
+		t.Errorf("expected the registered format got %s", format.Name)
+	}
+	if err := RegisterFormat(custom); err == nil {
+		t.Error("expected an error for a duplicate format")
+	}
+}
+
+func TestStitch(t *testing.T) {
+	reflectivity := func(q float64) float64 { return 1e-6 / math.Pow(q, 4) }
+
+	// two ranges overlapping between 0.08 and 0.1, the second one attenuated by 100
+	low, high := function.Points{}, function.Points{}
+	for q := 0.02; q <= 0.1001; q += 0.01 {
+		low = append(low, &function.Point{X: q, Y: reflectivity(q), Error: 0.01 * reflectivity(q)})
+	}
+	for q := 0.08; q <= 0.2001; q += 0.01 {
+		high = append(high, &function.Point{X: q, Y: reflectivity(q) / 100, Error: 0.01 * reflectivity(q) / 100})
+	}
+
+	result, err := Stitch([]function.Points{high, low}, DEFAULT_STITCH_TOLERANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scales[1] != 1 || math.Abs(result.Scales[0]-100) > 1e-6 || !(result.ScaleErrors[0] > 0) {
+		t.Errorf("expected scales 100 and 1 got %v ± %v", result.Scales, result.ScaleErrors)
+	}
+
+	// overlapping points are rebinned
+	if len(result.Points) != 19 {
+		t.Errorf("expected 19 points got %d", len(result.Points))
+	}
+	for _, p := range result.Points {
+		if math.Abs(p.Y-reflectivity(p.X)) > 1e-9*reflectivity(p.X) {
+			t.Errorf("expected %g at q=%g got %g", reflectivity(p.X), p.X, p.Y)
+		}
+	}
+
+	if _, err := Stitch([]function.Points{low, {{X: 0.5, Y: 1}}}, DEFAULT_STITCH_TOLERANCE); err == nil {
+		t.Error("expected an error without overlap")
+	}
+}
+
+// dense points closer than the tolerance outside the overlap are kept
+func TestStitchDense(t *testing.T) {
+	reflectivity := func(q float64) float64 { return 1e-6 / math.Pow(q, 4) }
+
+	// overlap between 0.06 and 0.07 on the same q values, steps of 0.001 above
+	low, high := function.Points{}, function.Points{}
+	for i := 20; i <= 70; i++ {
+		q := float64(i) / 1000
+		low = append(low, &function.Point{X: q, Y: reflectivity(q), Error: 0.01 * reflectivity(q)})
+	}
+	for i := 60; i <= 300; i++ {
+		q := float64(i) / 1000
+		high = append(high, &function.Point{X: q, Y: reflectivity(q) / 10, Error: 0.01 * reflectivity(q) / 10})
+	}
+
+	result, err := Stitch([]function.Points{low, high}, DEFAULT_STITCH_TOLERANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the 11 points of the overlap are rebinned
+	if expected := len(low) + len(high) - 11; len(result.Points) != expected {
+		t.Errorf("expected %d points got %d", expected, len(result.Points))
+	}
+	above := 0
+	for _, p := range result.Points {
+		if p.X > 0.0705 {
+			above++
+		}
+	}
+	if above != 230 {
+		t.Errorf("expected the 230 points above the overlap got %d", above)
+	}
+}
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// first line of every ORSO reflectivity text file (.ort)
+const ORSO_MAGIC = "# # ORSO reflectivity data file"
+
+// metadata keys of ORSO datasets
+const (
+	METADATA_DATASET     = "data_set"
+	METADATA_SAMPLE      = "sample"
+	METADATA_INSTRUMENT  = "instrument"
+	METADATA_PROBE       = "probe"
+	METADATA_OWNER       = "owner"
+	METADATA_UNITS       = "units"
+	METADATA_TEMPERATURE = "temperature"
+)
+
+// conversion of a full width at half maximum to the standard deviation of a gaussian
+var fwhmToSigma = 1 / (2 * math.Sqrt(2*math.Ln2))
+
+// IsORSO returns true if the data starts with the ORSO magic line
+func IsORSO(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), ORSO_MAGIC)
+}
+
+// orso column description, columns are either a quantity (name, unit) or the error of a quantity
+type orsoColumn struct {
+	Name      string `yaml:"name"`
+	Unit      string `yaml:"unit"`
+	ErrorOf   string `yaml:"error_of"`
+	ErrorType string `yaml:"error_type"`
+	ValueIs   string `yaml:"value_is"`
+}
+
+// ParseORSO parses an ORSO reflectivity text file (.ort) with one or more datasets
+// the yaml header of the following datasets only contains changes to the header of the first dataset.
+// The columns are Qz, R, the error of R and the resolution of Qz (checked by their error_of), further columns are ignored.
+// Qz in 1/nm is converted to 1/Å, fwhm errors to standard deviations
+func ParseORSO(data []byte) ([]*Dataset, error) {
+	if !IsORSO(data) {
+		return nil, errors.New("orso error: missing '" + ORSO_MAGIC + "' line")
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	datasets := make([]*Dataset, 0)
+	header := make(map[string]any)
+	block := make([]string, 0)
+	blockStart := 0
+	var points function.Points
+	var columns []orsoColumn
+
+	// finishes the dataset of the current header and data lines
+	finish := func() error {
+		if points == nil {
+			return nil
+		}
+		dataset, err := newORSODataset(header, columns, points, len(datasets))
+		if err != nil {
+			return err
+		}
+		datasets = append(datasets, dataset)
+		points = nil
+		return nil
+	}
+
+	for i, v := range lines {
+		lineNumber := i + 1
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if strings.HasPrefix(v, "#") {
+			// a header after data lines starts the next dataset
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			if len(block) == 0 {
+				blockStart = lineNumber
+			}
+			// "# # " lines are comments (magic line, column labels)
+			if !strings.HasPrefix(v, "# #") {
+				block = append(block, strings.TrimPrefix(strings.TrimPrefix(v, "#"), " "))
+			}
+			continue
+		}
+
+		// first data line after a header
+		if len(block) > 0 {
+			var err error
+			columns, err = mergeORSOHeader(header, block)
+			if err != nil {
+				return nil, fmt.Errorf("orso error in header starting in line %d: %v", blockStart, err)
+			}
+			block = block[:0]
+		}
+
+		values, err := parseFloats(strings.Fields(v))
+		if err != nil {
+			return nil, fmt.Errorf("orso error in line %d: %v", lineNumber, err)
+		}
+		if len(values) < 2 {
+			return nil, fmt.Errorf("orso error in line %d: expected at least 2 columns (Qz, R) got %d", lineNumber, len(values))
+		}
+
+		point := &function.Point{X: values[0], Y: values[1]}
+		if len(values) > 2 {
+			point.Error = values[2]
+		}
+		if len(values) > 3 {
+			point.Resolution = values[3]
+		}
+		points = append(points, point)
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	if len(datasets) == 0 {
+		return nil, errors.New("orso error: no data")
+	}
+	return datasets, nil
+}
+
+// merges the yaml header lines into the header and returns its columns
+func mergeORSOHeader(header map[string]any, block []string) ([]orsoColumn, error) {
+	changes := make(map[string]any)
+	if err := yaml.Unmarshal([]byte(strings.Join(block, "\n")), &changes); err != nil {
+		return nil, err
+	}
+	mergeMaps(header, changes)
+
+	raw, ok := header["columns"]
+	if !ok {
+		return nil, errors.New("missing columns")
+	}
+	encoded, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]orsoColumn, 0)
+	if err := yaml.Unmarshal(encoded, &columns); err != nil {
+		return nil, fmt.Errorf("invalid columns: %v", err)
+	}
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("expected at least 2 columns (Qz, R) got %d", len(columns))
+	}
+	// the optional third and fourth column are the errors of R and Qz
+	if len(columns) > 2 && !columns[2].isErrorOf(columns[1].Name) {
+		return nil, fmt.Errorf("column 3 has to be the error of %s", columns[1].Name)
+	}
+	if len(columns) > 3 && !columns[3].isErrorOf(columns[0].Name) {
+		return nil, fmt.Errorf("column 4 has to be the error of %s", columns[0].Name)
+	}
+	return columns, nil
+}
+
+// returns true if the column is the error of the named column (error_of or the name "s" + name)
+func (c orsoColumn) isErrorOf(name string) bool {
+	if c.ErrorOf != "" {
+		return strings.EqualFold(c.ErrorOf, name)
+	}
+	return strings.EqualFold(c.Name, "s"+name)
+}
+
+// recursively overrides the entries of dst with the entries of src
+func mergeMaps(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// creates a dataset from the points of a header, the qz column is converted to 1/Å
+func newORSODataset(header map[string]any, columns []orsoColumn, points function.Points, index int) (*Dataset, error) {
+	metadata := make(map[string]string)
+	setMetadata := func(key string, path ...string) {
+		if value, ok := lookup(header, path...); ok && value != "" {
+			metadata[key] = value
+		}
+	}
+	setMetadata(METADATA_DATASET, "data_set")
+	setMetadata(METADATA_SAMPLE, "data_source", "sample", "name")
+	setMetadata(METADATA_INSTRUMENT, "data_source", "experiment", "instrument")
+	setMetadata(METADATA_PROBE, "data_source", "experiment", "probe")
+	setMetadata(METADATA_OWNER, "data_source", "owner", "name")
+	setMetadata(METADATA_TEMPERATURE, "data_source", "sample", "sample_parameters", "temperature", "magnitude")
+	if _, ok := metadata[METADATA_DATASET]; !ok {
+		metadata[METADATA_DATASET] = fmt.Sprint(index)
+	}
+
+	units := make([]string, 0, len(columns))
+	for _, c := range columns {
+		name := c.Name
+		if c.ErrorOf != "" {
+			name = "s" + c.ErrorOf
+		}
+		if c.Unit != "" {
+			name += " (" + c.Unit + ")"
+		}
+		units = append(units, name)
+	}
+	metadata[METADATA_UNITS] = strings.Join(units, ", ")
+
+	// errors given as fwhm
+	isFWHM := func(i int) bool {
+		return i < len(columns) && strings.EqualFold(columns[i].ValueIs, "FWHM")
+	}
+	for _, p := range points {
+		if isFWHM(2) {
+			p.Error *= fwhmToSigma
+		}
+		if isFWHM(3) {
+			p.Resolution *= fwhmToSigma
+		}
+	}
+
+	unit := QZ_ANGSTROM
+	switch strings.ToLower(strings.ReplaceAll(columns[0].Unit, " ", "")) {
+	case "", "1/angstrom", "1/å", "1/a":
+	case "1/nm":
+		unit = QZ_NANOMETER
+	default:
+		return nil, fmt.Errorf("orso error: unsupported Qz unit '%s'", columns[0].Unit)
+	}
+
+	dataset, err := Columns{X: unit}.Convert(points)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range metadata {
+		dataset.Metadata[key] = value
+	}
+	return dataset, nil
+}
+
+// returns the value at the path of nested maps as string
+func lookup(header map[string]any, path ...string) (string, bool) {
+	var value any = header
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[key]; !ok || value == nil {
+			return "", false
+		}
+	}
+	if _, ok := value.(map[string]any); ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
@@ -0,0 +1,202 @@
+package data
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+	"slices"
+)
+
+// relative q distance below which points of overlapping tracks are rebinned into one point
+const DEFAULT_STITCH_TOLERANCE = 0.005
+
+// StitchResult is the merged track and the scale factors applied to the input tracks
+type StitchResult struct {
+	Points function.Points
+	// scale factor of every input track (in input order) relative to the track with the lowest q
+	Scales []float64
+	// standard deviation of the scale factors
+	ScaleErrors []float64
+}
+
+// Stitch merges reflectivity tracks measured in overlapping q ranges (e.g. with different attenuators)
+// the tracks are sorted by their lowest q, every track is scaled onto the merged tracks before it by the weighted
+// mean ratio in their overlap, the error of the scale factor is propagated into the scaled points.
+// Inside the overlap a point of the track and a point of the merged tracks closer than tolerance*q are rebinned
+// into their weighted mean, all other points are kept unchanged
+func Stitch(tracks []function.Points, tolerance float64) (*StitchResult, error) {
+	if len(tracks) < 2 {
+		return nil, errors.New("stitch error: at least 2 tracks needed")
+	}
+	for i, track := range tracks {
+		if len(track) == 0 {
+			return nil, fmt.Errorf("stitch error: track %d has no points", i+1)
+		}
+	}
+
+	// tracks in the order of their lowest q
+	order := make([]int, len(tracks))
+	for i := range order {
+		order[i] = i
+	}
+	sorted := make([]function.Points, len(tracks))
+	for i, track := range tracks {
+		sorted[i] = track.Copy()
+		sorted[i].Sort()
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(sorted[a][0].X, sorted[b][0].X)
+	})
+
+	result := &StitchResult{
+		Scales:      make([]float64, len(tracks)),
+		ScaleErrors: make([]float64, len(tracks)),
+	}
+	result.Scales[order[0]] = 1
+	merged := sorted[order[0]]
+
+	for _, i := range order[1:] {
+		track := sorted[i]
+		scale, scaleError, err := overlapScale(merged, track)
+		if err != nil {
+			return nil, fmt.Errorf("stitch error: track %d: %v", i+1, err)
+		}
+		result.Scales[i], result.ScaleErrors[i] = scale, scaleError
+
+		scaled := make(function.Points, len(track))
+		for j, p := range track {
+			scaled[j] = &function.Point{
+				X:          p.X,
+				Y:          scale * p.Y,
+				Error:      math.Hypot(scale*p.Error, scaleError*p.Y),
+				Resolution: p.Resolution,
+			}
+		}
+		merged = rebin(merged, scaled, tolerance)
+	}
+
+	result.Points = merged
+	return result, nil
+}
+
+// returns the weighted mean ratio reference/track of the track points inside the q range of the reference
+func overlapScale(reference, track function.Points) (float64, float64, error) {
+	minQ, maxQ := reference[0].X, reference[len(reference)-1].X
+
+	ratios := make(function.Points, 0)
+	for _, p := range track {
+		if p.X < minQ || p.X > maxQ || p.Y <= 0 {
+			continue
+		}
+		y, err := function.Interpolate(reference, p.X)
+		if err != nil || y <= 0 {
+			continue
+		}
+		yError := interpolateError(reference, p.X)
+
+		ratio := y / p.Y
+		ratios = append(ratios, &function.Point{X: p.X, Y: ratio, Error: ratio * math.Hypot(yError/y, p.Error/p.Y)})
+	}
+	if len(ratios) == 0 {
+		return 0, 0, fmt.Errorf("no overlap with the q range %g - %g", minQ, maxQ)
+	}
+
+	mean := weightedMean(ratios)
+	if slices.ContainsFunc(ratios, func(p *function.Point) bool { return !(p.Error > 0) }) {
+		// ratios without errors, the error of the scale is the standard error of their mean
+		variance := 0.0
+		for _, r := range ratios {
+			variance += (r.Y - mean.Y) * (r.Y - mean.Y)
+		}
+		mean.Error = 0
+		if len(ratios) > 1 {
+			mean.Error = math.Sqrt(variance / float64(len(ratios)*(len(ratios)-1)))
+		}
+	}
+	return mean.Y, mean.Error, nil
+}
+
+// returns the linearly interpolated error of the sorted points at x
+func interpolateError(points function.Points, x float64) float64 {
+	i, found := slices.BinarySearchFunc(points, x, func(p *function.Point, x float64) int {
+		return cmp.Compare(p.X, x)
+	})
+	if found || i == 0 {
+		return points[i].Error
+	}
+	if i == len(points) {
+		return points[i-1].Error
+	}
+	lp, up := points[i-1], points[i]
+	return lp.Error + (up.Error-lp.Error)*(x-lp.X)/(up.X-lp.X)
+}
+
+// merges the sorted points of the merged tracks and of a track, inside their overlap a point of either one
+// is combined with the nearest point of the other one closer than tolerance*q into their error weighted mean.
+// Points outside the overlap are never combined, so dense points of a single track are kept
+func rebin(merged, track function.Points, tolerance float64) function.Points {
+	minQ := math.Max(merged[0].X, track[0].X)
+	maxQ := math.Min(merged[len(merged)-1].X, track[len(track)-1].X)
+	inOverlap := func(p *function.Point) bool { return p.X >= minQ && p.X <= maxQ }
+	distance := func(a, b *function.Point) float64 { return math.Abs(a.X - b.X) }
+
+	rebinned := make(function.Points, 0, len(merged)+len(track))
+	i, j := 0, 0
+	for i < len(merged) && j < len(track) {
+		a, b := merged[i], track[j]
+		pair := inOverlap(a) && inOverlap(b) && distance(a, b) <= tolerance*math.Min(math.Abs(a.X), math.Abs(b.X))
+		// the next point of either one is closer, it is paired instead
+		nextA := i+1 < len(merged) && distance(merged[i+1], b) < distance(a, b)
+		nextB := j+1 < len(track) && distance(a, track[j+1]) < distance(a, b)
+
+		switch {
+		case pair && !nextA && !nextB:
+			rebinned = append(rebinned, weightedMean(function.Points{a, b}))
+			i++
+			j++
+		case a.X <= b.X:
+			rebinned = append(rebinned, a)
+			i++
+		default:
+			rebinned = append(rebinned, b)
+			j++
+		}
+	}
+	rebinned = append(rebinned, merged[i:]...)
+	return append(rebinned, track[j:]...)
+}
+
+// returns the error weighted mean of the points, points without errors are weighted equally
+func weightedMean(points function.Points) *function.Point {
+	if len(points) == 1 {
+		return points[0]
+	}
+
+	weighted := !slices.ContainsFunc(points, func(p *function.Point) bool { return !(p.Error > 0) })
+	mean := &function.Point{}
+	sumWeights := 0.0
+	for _, p := range points {
+		weight := 1.0
+		if weighted {
+			weight = 1 / (p.Error * p.Error)
+		}
+		sumWeights += weight
+		mean.X += weight * p.X
+		mean.Y += weight * p.Y
+		mean.Resolution += weight * p.Resolution
+		if !weighted {
+			mean.Error += p.Error * p.Error
+		}
+	}
+	mean.X /= sumWeights
+	mean.Y /= sumWeights
+	mean.Resolution /= sumWeights
+	if weighted {
+		mean.Error = 1 / math.Sqrt(sumWeights)
+	} else {
+		mean.Error = math.Sqrt(mean.Error) / float64(len(points))
+	}
+	return mean
+}
@@ -11,6 +11,12 @@ type Function struct {
 	data  Points
 	Scope *Scope
 	eval  InterpolationFunction
+
+	// optional origin of the data (name, source file, sample, ...), nil for calculated functions
+	Metadata *Metadata
+
+	// optional points excluded from fits, nil if nothing is masked
+	Mask *Mask
 }
 
 type Functions []*Function
@@ -130,6 +136,9 @@ func (f *Function) SetData(data Points) {
 			minY,
 			maxY,
 		}
+	} else {
+		// empty functions (e.g. switched off model curves) do not extend the scope of a graph
+		f.Scope = nil
 	}
 }
 
@@ -138,6 +147,15 @@ func (f *Function) GetData() Points {
 	return f.data
 }
 
+// returns the points which are not masked
+func (f *Function) GetUnmaskedData() Points {
+	if f.Mask.IsEmpty() {
+		return f.data
+	}
+	unmasked, _ := f.Mask.Split(f.data)
+	return unmasked
+}
+
 // returns how many points the function consists of
 func (f *Function) GetDataCount() int {
 	return len(f.data)
@@ -1,7 +1,10 @@
 package function
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"slices"
 )
 
 type InterpolationFunction func(points Points, x float64) (float64, error)
@@ -18,27 +21,46 @@ func simpleEvaluation(points Points, x float64) (float64, error) {
 	return -1, fmt.Errorf("evaluation error: function not defined at %f", x)
 }
 
+// interpolates linearly between the points, the points need to be sorted by X value
+// x values below the first point are extrapolated with the first two points, above the last point it fails
 func linearInterpolation(points Points, x float64) (float64, error) {
-	var lower = 0
-	var upper = 1
-
-	for i, v := range points {
-		if v.X < x {
-			lower = i
-			upper = i + 1
-		} else {
-			break
-		}
+	if len(points) > 1 && x < points[0].X {
+		lp, up := points[0], points[1]
+		return linearBetween(lp, up, (x-lp.X)/(up.X-lp.X)), nil
 	}
+	return interpolate(points, x, linearBetween)
+}
 
-	if upper == len(points) {
-		return -1, fmt.Errorf("linearInterpolator eval error: out of bounds. %f is not in the scope (%f, %f)", x, points[0], points[lower])
-	}
+// interpolates the logarithm of the y values linearly, the points need to be sorted by X value
+// between points with a non positive y value it interpolates linearly, x values outside the points are not extrapolated
+func logInterpolation(points Points, x float64) (float64, error) {
+	return interpolate(points, x, func(lp, up *Point, t float64) float64 {
+		if lp.Y <= 0 || up.Y <= 0 {
+			return linearBetween(lp, up, t)
+		}
+		return math.Exp(math.Log(lp.Y) + t*(math.Log(up.Y)-math.Log(lp.Y)))
+	})
+}
 
-	lp := points[lower]
-	up := points[upper]
-	m := (up.Y - lp.Y) / (up.X - lp.X)
-	b := lp.Y - m*lp.X
+// returns the y value at the relative position t between the lower and the upper point
+func linearBetween(lp, up *Point, t float64) float64 {
+	return lp.Y + t*(up.Y-lp.Y)
+}
+
+// finds the neighbouring points of x in the sorted points and interpolates between them,
+// t is the relative position of x between the lower and the upper point.
+// x values outside the range of the points are not extrapolated
+func interpolate(points Points, x float64, between func(lp, up *Point, t float64) float64) (float64, error) {
+	i, found := slices.BinarySearchFunc(points, x, func(p *Point, x float64) int {
+		return cmp.Compare(p.X, x)
+	})
+	if found {
+		return points[i].Y, nil
+	}
+	if i == 0 || i == len(points) {
+		return -1, fmt.Errorf("interpolation error: %f is out of range", x)
+	}
 
-	return m*x + b, nil
+	lp, up := points[i-1], points[i]
+	return between(lp, up, (x-lp.X)/(up.X-lp.X)), nil
 }
@@ -0,0 +1,71 @@
+package function
+
+import (
+	"slices"
+)
+
+// Mask excludes points of a function (e.g. detector glitches of a data track) from fits, the data itself is not changed
+// points are identified by their x value, so the mask stays valid when the points are sorted or filtered
+type Mask struct {
+	// masked x ranges (including their limits)
+	Ranges []MaskRange `json:"ranges,omitempty" xml:"ranges,omitempty"`
+	// x values of single masked points
+	Points []float64 `json:"points,omitempty" xml:"points,omitempty"`
+}
+
+// MaskRange is a masked x range
+type MaskRange struct {
+	Min float64 `json:"min" xml:"min"`
+	Max float64 `json:"max" xml:"max"`
+}
+
+// returns true if the point at x is masked, a nil mask masks nothing
+func (m *Mask) IsMasked(x float64) bool {
+	if m == nil {
+		return false
+	}
+	if slices.Contains(m.Points, x) {
+		return true
+	}
+	return slices.ContainsFunc(m.Ranges, func(r MaskRange) bool { return x >= r.Min && x <= r.Max })
+}
+
+// masks the x range, the limits may be given in any order
+func (m *Mask) AddRange(minX, maxX float64) {
+	m.Ranges = append(m.Ranges, MaskRange{Min: min(minX, maxX), Max: max(minX, maxX)})
+}
+
+// masks the single point at x or unmasks it if it is masked as single point, points in masked ranges stay masked
+func (m *Mask) TogglePoint(x float64) {
+	if i := slices.Index(m.Points, x); i != -1 {
+		m.Points = slices.Delete(m.Points, i, i+1)
+		return
+	}
+	m.Points = append(m.Points, x)
+}
+
+// returns true if nothing is masked
+func (m *Mask) IsEmpty() bool {
+	return m == nil || (len(m.Ranges) == 0 && len(m.Points) == 0)
+}
+
+// returns a deep copy of the mask
+func (m *Mask) Copy() *Mask {
+	if m == nil {
+		return nil
+	}
+	return &Mask{Ranges: slices.Clone(m.Ranges), Points: slices.Clone(m.Points)}
+}
+
+// splits the points into the unmasked and the masked points
+func (m *Mask) Split(points Points) (unmasked Points, masked Points) {
+	unmasked = make(Points, 0, len(points))
+	for _, p := range points {
+		if m.IsMasked(p.X) {
+			masked = append(masked, p)
+		} else {
+			unmasked = append(unmasked, p)
+		}
+	}
+	return unmasked, masked
+}
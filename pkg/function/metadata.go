@@ -0,0 +1,84 @@
+package function
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Metadata describes the origin of the data of a function (e.g. an imported measurement)
+type Metadata struct {
+	// name shown in the legend of the graph
+	Name string `json:"name" xml:"name"`
+	// path of the imported file
+	Source string `json:"source,omitempty" xml:"source,omitempty"`
+	// time of the import
+	ImportTime time.Time `json:"import_time" xml:"import_time"`
+	Sample     string    `json:"sample,omitempty" xml:"sample,omitempty"`
+	// sample temperature in K, 0 if unknown
+	Temperature float64 `json:"temperature,omitempty" xml:"temperature,omitempty"`
+	Instrument  string  `json:"instrument,omitempty" xml:"instrument,omitempty"`
+	// units of the columns in the imported file
+	Units string `json:"units,omitempty" xml:"units,omitempty"`
+	// free-form key/value pairs sorted by key (e.g. probe, owner)
+	Values []MetadataValue `json:"values,omitempty" xml:"values,omitempty"`
+}
+
+// MetadataValue is a free-form key/value pair of the metadata
+type MetadataValue struct {
+	Key   string `json:"key" xml:"key"`
+	Value string `json:"value" xml:"value"`
+}
+
+// returns the free-form value of the key
+func (m *Metadata) Get(key string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	i := slices.IndexFunc(m.Values, func(v MetadataValue) bool { return v.Key == key })
+	if i == -1 {
+		return "", false
+	}
+	return m.Values[i].Value, true
+}
+
+// sets the free-form value of the key, the values stay sorted by key
+func (m *Metadata) Set(key, value string) {
+	i, found := slices.BinarySearchFunc(m.Values, key, func(v MetadataValue, key string) int {
+		return strings.Compare(v.Key, key)
+	})
+	if found {
+		m.Values[i].Value = value
+		return
+	}
+	m.Values = slices.Insert(m.Values, i, MetadataValue{Key: key, Value: value})
+}
+
+// returns a deep copy of the metadata
+func (m *Metadata) Copy() *Metadata {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Values = slices.Clone(m.Values)
+	return &c
+}
+
+// returns the legend entry, the name with sample and temperature if known
+func (m *Metadata) Label() string {
+	if m == nil {
+		return ""
+	}
+	details := make([]string, 0, 2)
+	if m.Sample != "" {
+		details = append(details, m.Sample)
+	}
+	if m.Temperature > 0 {
+		details = append(details, fmt.Sprintf("%g K", m.Temperature))
+	}
+	if len(details) == 0 {
+		return m.Name
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, strings.Join(details, ", "))
+}
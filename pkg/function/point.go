@@ -8,10 +8,12 @@ import (
 )
 
 // represents a point with x and y value and and error value
+// the optional resolution describes the standard deviation of the x value (f.e. dQ of a measurement)
 type Point struct {
-	X     float64
-	Y     float64
-	Error float64
+	X          float64
+	Y          float64
+	Error      float64
+	Resolution float64
 }
 
 type Points []*Point
@@ -37,6 +39,18 @@ func GetY(points Points, x float64) (float64, error) {
 	return -1, fmt.Errorf("evaluation error: x not found %f", x)
 }
 
+// returns the linearly interpolated y-value for a x-value, the points need to be sorted by X value
+// x-values below the first point are extrapolated, above the last point an error is returned
+func Interpolate(points Points, x float64) (float64, error) {
+	return linearInterpolation(points, x)
+}
+
+// returns the y-value for a x-value interpolating the logarithm of the y-values (e.g. reflectivities decaying over decades)
+// the points need to be sorted by X value, between non positive y-values it interpolates linearly
+func LogInterpolate(points Points, x float64) (float64, error) {
+	return logInterpolation(points, x)
+}
+
 // returns min and max X or Y value of all points
 func (p Points) MinMaxXY() (minX float64, maxX float64, minY float64, maxY float64) {
 	if len(p) == 0 {
@@ -101,9 +115,10 @@ func (p Points) Copy() Points {
 	np := make(Points, len(p))
 	for i, point := range p {
 		np[i] = &Point{
-			X:     point.X,
-			Y:     point.Y,
-			Error: point.Error,
+			X:          point.X,
+			Y:          point.Y,
+			Error:      point.Error,
+			Resolution: point.Resolution,
 		}
 	}
 
@@ -116,9 +131,10 @@ func (p Points) Filter(min, max float64) Points {
 	for _, point := range p {
 		if point.X >= min && point.X <= max {
 			np = append(np, &Point{
-				X:     point.X,
-				Y:     point.Y,
-				Error: point.Error,
+				X:          point.X,
+				Y:          point.Y,
+				Error:      point.Error,
+				Resolution: point.Resolution,
 			})
 		}
 	}
@@ -2,6 +2,7 @@ package function
 
 import (
 	"cmp"
+	"math"
 	"slices"
 	"testing"
 
@@ -29,6 +30,56 @@ func TestPoint(t *testing.T) {
 	spew.Dump(points)
 }
 
+// test if interpolation hits the points and fails outside their range
+func TestInterpolate(t *testing.T) {
+	points := Points{
+		{X: 1, Y: 2},
+		{X: 3, Y: 6},
+		{X: 4, Y: 0},
+	}
+
+	// below the first point the first segment is extrapolated
+	for x, expected := range map[float64]float64{0.5: 1, 1: 2, 2: 4, 3: 6, 3.5: 3, 4: 0} {
+		y, err := Interpolate(points, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y != expected {
+			t.Errorf("expected %f at %f got %f", expected, x, y)
+		}
+	}
+
+	if _, err := Interpolate(points, 4.5); err == nil {
+		t.Errorf("expected an error at %f", 4.5)
+	}
+}
+
+// test if the logarithm is interpolated between positive points
+func TestLogInterpolate(t *testing.T) {
+	points := Points{
+		{X: 1, Y: 1},
+		{X: 3, Y: 100},
+		{X: 4, Y: 0},
+	}
+
+	for x, expected := range map[float64]float64{1: 1, 2: 10, 3: 100, 3.5: 50} {
+		y, err := LogInterpolate(points, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(y-expected) > 1e-9 {
+			t.Errorf("expected %f at %f got %f", expected, x, y)
+		}
+	}
+
+	// the cost must not compare data to an extrapolated intensity
+	for _, x := range []float64{0.5, 4.5} {
+		if _, err := LogInterpolate(points, x); err == nil {
+			t.Errorf("expected an error at %f", x)
+		}
+	}
+}
+
 type PointT[T any] struct {
 	X T
 }
@@ -58,3 +109,48 @@ func TestSort(t *testing.T) {
 
 	testSortFunc(points)
 }
+
+func TestMetadata(t *testing.T) {
+	metadata := &Metadata{Name: "run.ort spin_up", Sample: "Ni on Si", Temperature: 300}
+	metadata.Set("probe", "neutron")
+	metadata.Set("owner", "someone")
+	metadata.Set("probe", "x-ray")
+
+	if probe, ok := metadata.Get("probe"); !ok || probe != "x-ray" || len(metadata.Values) != 2 || metadata.Values[0].Key != "owner" {
+		t.Errorf("expected sorted values with probe x-ray got %v", metadata.Values)
+	}
+	if label := metadata.Label(); label != "run.ort spin_up (Ni on Si, 300 K)" {
+		t.Errorf("unexpected label '%s'", label)
+	}
+
+	c := metadata.Copy()
+	c.Set("probe", "neutron")
+	if probe, _ := metadata.Get("probe"); probe != "x-ray" {
+		t.Error("expected the copy to not change the original values")
+	}
+
+	var empty *Metadata
+	if _, ok := empty.Get("probe"); ok || empty.Label() != "" || empty.Copy() != nil {
+		t.Error("expected nil metadata to be empty")
+	}
+}
+
+func TestMask(t *testing.T) {
+	f := NewFunction(Points{{X: 0.01, Y: 1}, {X: 0.02, Y: 0.5}, {X: 0.03, Y: 0.2}, {X: 0.04, Y: 0.1}})
+	if len(f.GetUnmaskedData()) != 4 {
+		t.Error("expected no masked points without mask")
+	}
+
+	f.Mask = &Mask{}
+	f.Mask.AddRange(0.035, 0.025)
+	f.Mask.TogglePoint(0.01)
+	unmasked := f.GetUnmaskedData()
+	if len(unmasked) != 2 || unmasked[0].X != 0.02 || unmasked[1].X != 0.04 {
+		t.Errorf("expected the points at 0.02 and 0.04 unmasked got %d points", len(unmasked))
+	}
+
+	f.Mask.TogglePoint(0.01)
+	if f.Mask.IsMasked(0.01) || !f.Mask.IsMasked(0.03) || len(f.GetData()) != 4 {
+		t.Error("expected toggling to unmask the point and the data to be unchanged")
+	}
+}
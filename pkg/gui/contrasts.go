@@ -0,0 +1,137 @@
+package gui
+
+import (
+	"errors"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/physics"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// name of the graph selector assigning data tracks to contrasts
+const contrastSelector = "contrast"
+
+// parameters of the current fit, set up when the minimizer is started
+var currentFitLayout *fitLayout
+
+// fitLayout maps the parameters passed to minuit to the model parameters of every contrast
+// shared parameters appear once, local parameters once per contrast
+type fitLayout struct {
+	parameters []*param.Parameter[float64]
+
+	// index in parameters of every model parameter (order of newModelState) per contrast
+	layout *minimizer.SharedLayout
+}
+
+// creates the fit layout of the current model parameters
+func newFitLayout() (*fitLayout, error) {
+	contrasts := make([][]*param.Parameter[float64], contrastCount())
+	for c := range contrasts {
+		contrasts[c] = contrastParameters(c)
+		if slices.Contains(contrasts[c], nil) {
+			return nil, errors.New("model parameter missing")
+		}
+	}
+
+	layout, parameters := minimizer.NewSharedLayout(contrasts)
+	return &fitLayout{parameters: parameters, layout: layout}, nil
+}
+
+// returns the model parameter values of a contrast
+// the parameters need to match the layout, it changes when layers, knots or contrasts are edited
+func (l *fitLayout) values(params []float64, contrast int) ([]float64, error) {
+	return l.layout.Values(params, contrast)
+}
+
+// returns the current values of the parameters
+func (l *fitLayout) currentValues() ([]float64, error) {
+	values := make([]float64, len(l.parameters))
+	for i, p := range l.parameters {
+		value, err := p.Get()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// returns the number of model instances, a model without contrasts has one
+func contrastCount() int {
+	return max(1, len(modelDefinition.Contrasts))
+}
+
+// returns the parameter group of the local parameters of a contrast
+func contrastGroup(contrast int) string {
+	return "contrast " + modelDefinition.Contrasts[contrast]
+}
+
+// returns the key of a function of a graph in the functionMap
+// every graph has its own functions (calculated on its qz axis), models with contrasts have them once per contrast
+func functionKey(graphId, identifier string, contrast int) string {
+	if len(modelDefinition.Contrasts) == 0 {
+		return graphId + "/" + identifier
+	}
+	return graphId + "/" + identifier + "@" + modelDefinition.Contrasts[contrast]
+}
+
+// reports whether every contrast has its own copy of a parameter
+func isLocalParameter(group, name string) bool {
+	return len(modelDefinition.Contrasts) > 0 && slices.ContainsFunc(modelDefinition.LocalParameters, func(p io.ParameterReference) bool {
+		return p.Group == group && p.Name == name
+	})
+}
+
+// returns the model parameters of a contrast in the order expected by newModelState
+// local parameters are replaced by the copy of the contrast
+func contrastParameters(contrast int) []*param.Parameter[float64] {
+	parameters := modelParameters()
+	if len(modelDefinition.Contrasts) == 0 {
+		return parameters
+	}
+
+	for i, spec := range modelParameterSpecs() {
+		if !isLocalParameter(spec.Group, spec.Name) {
+			continue
+		}
+		if group := param.GetFloatGroup(contrastGroup(contrast)); group != nil && group.GetParam(spec.Name) != nil {
+			parameters[i] = group.GetParam(spec.Name)
+		}
+	}
+
+	return parameters
+}
+
+// creates the local parameters of every contrast, one row per contrast
+// defaults, limits and fit flags are taken from the definition in the contrast group or else from the shared parameter
+func buildContrastParams() []fyne.CanvasObject {
+	known := append(modelParameterSpecs(), additionalParameterSpecs()...)
+
+	rows := make([]fyne.CanvasObject, 0, len(modelDefinition.Contrasts))
+	for c, name := range modelDefinition.Contrasts {
+		objects := make([]fyne.CanvasObject, 0, len(modelDefinition.LocalParameters))
+		for _, local := range modelDefinition.LocalParameters {
+			spec := physics.ParameterSpec{Group: contrastGroup(c), Name: local.Name}
+			if i := slices.IndexFunc(known, func(s physics.ParameterSpec) bool {
+				return s.Group == local.Group && s.Name == local.Name
+			}); i != -1 {
+				spec.Default = known[i].Default
+			}
+
+			definition := findParameterDefinition(spec.Group, spec.Name)
+			if definition == nil {
+				definition = findParameterDefinition(local.Group, local.Name)
+			}
+			objects = append(objects, createParameterFromDefinition(spec, definition, slices.Contains(layerGroupOrder, local.Group)))
+		}
+
+		rows = append(rows, container.NewBorder(nil, nil, widget.NewLabel(name), nil, container.NewGridWithColumns(4, objects...)))
+	}
+
+	return rows
+}
@@ -117,6 +117,7 @@ type MinimizerControlPanel struct {
 	lblFVal          *widget.Label
 	lblError         *widget.Label
 	lblStatus        *widget.Label
+	selCost          *widget.Select
 	oldMinimizerData []float64
 	sharedStorage    *SharedMinimizerData
 }
@@ -133,6 +134,7 @@ func NewMinimizerControlPanel() *MinimizerControlPanel {
 		lblFVal:          widget.NewLabel("FVal: -"),
 		lblError:         widget.NewLabel(""),
 		lblStatus:        widget.NewLabel("Not Initialized"),
+		selCost:          newCostSetting(),
 		oldMinimizerData: nil,
 		sharedStorage:    &SharedMinimizerData{},
 	}
@@ -152,7 +154,7 @@ func NewMinimizerControlPanel() *MinimizerControlPanel {
 }
 
 func (controlPanel *MinimizerControlPanel) Widget() fyne.CanvasObject {
-	return container.NewHBox(controlPanel.btnStart, controlPanel.btnContinue, controlPanel.btnPause, controlPanel.btnStop, helper.CreateSeparator(), container.NewVBox(container.NewHBox(controlPanel.lblError, controlPanel.lblFVal, controlPanel.lblNCalls), helper.CreateSeparator(), controlPanel.lblStatus))
+	return container.NewHBox(controlPanel.btnStart, controlPanel.btnContinue, controlPanel.btnPause, controlPanel.btnStop, widget.NewLabel("Cost"), controlPanel.selCost, helper.CreateSeparator(), container.NewVBox(container.NewHBox(controlPanel.lblError, controlPanel.lblFVal, controlPanel.lblNCalls), helper.CreateSeparator(), controlPanel.lblStatus))
 }
 
 func (controlPanel *MinimizerControlPanel) Pause() {
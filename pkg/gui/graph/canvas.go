@@ -25,6 +25,32 @@ type GraphCanvas struct {
 	functions         function.Functions
 	loadedData        function.Functions
 	dataRemoveButtons []*fyne.Container
+
+	// selectors of the data tracks (channels and Config.Selectors)
+	// and the selected option and select widget of every data track for every selector
+	selectors      []TrackSelector
+	dataSelections [][]int
+	dataSelects    [][]*widget.Select
+
+	// selected display transform (index of Config.Transforms) and its select, nil for less than two transforms
+	transform       int
+	transformSelect *widget.Select
+
+	// buttons of Config.Actions
+	actionButtons []*widget.Button
+
+	// check boxes of Config.Toggles
+	toggleChecks []*widget.Check
+
+	// drawn positions of the data points, a click masks the nearest one
+	dataPointPositions []dataPointPosition
+}
+
+// drawn position of the point at x of a data track (index of loadedData)
+type dataPointPosition struct {
+	track    int
+	x        float64
+	position fyne.Position
 }
 
 // NewGraphCanvas creates a new canvas instance with a provided config
@@ -44,6 +70,42 @@ func NewGraphCanvas(config *GraphConfig) *GraphCanvas {
 		loadedData: make(function.Functions, 0),
 	}
 
+	// only selectors with a choice get a select
+	if len(config.Channels) > 1 {
+		g.selectors = append(g.selectors, TrackSelector{Name: CHANNEL_SELECTOR, Options: config.Channels})
+	}
+	for _, s := range config.Selectors {
+		if len(s.Options) > 1 {
+			g.selectors = append(g.selectors, s)
+		}
+	}
+
+	if len(config.Transforms) > 1 {
+		names := make([]string, len(config.Transforms))
+		for i, t := range config.Transforms {
+			names[i] = t.Name()
+		}
+		g.transform = max(slices.Index(names, config.Transform), 0)
+		g.transformSelect = widget.NewSelect(names, nil)
+		g.transformSelect.SetSelectedIndex(g.transform)
+		g.transformSelect.OnChanged = g.SetTransform
+	}
+
+	for _, action := range config.Actions {
+		g.actionButtons = append(g.actionButtons, widget.NewButton(action.Name, func() {
+			action.OnTapped(g)
+		}))
+	}
+
+	for _, toggle := range config.Toggles {
+		check := widget.NewCheck(toggle.Name, nil)
+		check.SetChecked(toggle.Checked)
+		check.OnChanged = func(checked bool) {
+			toggle.OnChanged(g, checked)
+		}
+		g.toggleChecks = append(g.toggleChecks, check)
+	}
+
 	for _, f := range g.functions {
 		if f == nil {
 			panic("function cannot be nil. Make sure to provide a function (even an empty one)")
@@ -84,6 +146,18 @@ func (g *GraphCanvas) AddDataTrack(dataTrack *function.Function) {
 	btnColor := DataTrackColors[i%len(DataTrackColors)]
 	g.dataRemoveButtons = append(g.dataRemoveButtons, container.NewStack(canvas.NewRectangle(btnColor), btnRemove))
 
+	// create selects of the selectors
+	selections := make([]int, len(g.selectors))
+	selects := make([]*widget.Select, len(g.selectors))
+	for s, selector := range g.selectors {
+		selects[s] = widget.NewSelect(selector.Options, func(option string) {
+			g.SetDataTrackSelection(dataTrack, selector.Name, slices.Index(selector.Options, option))
+		})
+		selects[s].SetSelectedIndex(0)
+	}
+	g.dataSelections = append(g.dataSelections, selections)
+	g.dataSelects = append(g.dataSelects, selects)
+
 	_ = minimizer.State.Set(1)
 	g.Refresh()
 }
@@ -97,9 +171,112 @@ func (g *GraphCanvas) RemoveDataTrack(dataTrack *function.Function) {
 	if i != -1 {
 		g.loadedData = append(g.loadedData[:i], g.loadedData[i+1:]...)
 		g.dataRemoveButtons = append(g.dataRemoveButtons[:i], g.dataRemoveButtons[i+1:]...)
+		g.dataSelections = append(g.dataSelections[:i], g.dataSelections[i+1:]...)
+		g.dataSelects = append(g.dataSelects[:i], g.dataSelects[i+1:]...)
 		g.Refresh()
+		g.dataTracksChanged()
 	}
 	if len(g.loadedData) == 0 {
 		_ = minimizer.State.Set(0)
 	}
 }
+
+// returns the channel (index of Config.Channels) of a data track, 0 if the graph has no channels
+func (g *GraphCanvas) GetDataTrackChannel(dataTrack *function.Function) int {
+	return g.GetDataTrackSelection(dataTrack, CHANNEL_SELECTOR)
+}
+
+// assigns a data track to a channel (index of Config.Channels)
+func (g *GraphCanvas) SetDataTrackChannel(dataTrack *function.Function, channel int) {
+	g.SetDataTrackSelection(dataTrack, CHANNEL_SELECTOR, channel)
+}
+
+// returns the selected option of a selector for a data track, 0 if the graph has no such selector
+func (g *GraphCanvas) GetDataTrackSelection(dataTrack *function.Function, selector string) int {
+	i := slices.Index(g.loadedData, dataTrack)
+	s := slices.IndexFunc(g.selectors, func(t TrackSelector) bool { return t.Name == selector })
+	if i == -1 || s == -1 {
+		return 0
+	}
+	return g.dataSelections[i][s]
+}
+
+// selects an option of a selector for a data track
+func (g *GraphCanvas) SetDataTrackSelection(dataTrack *function.Function, selector string, option int) {
+	i := slices.Index(g.loadedData, dataTrack)
+	s := slices.IndexFunc(g.selectors, func(t TrackSelector) bool { return t.Name == selector })
+	if i == -1 || s == -1 || option < 0 || option >= len(g.selectors[s].Options) || g.dataSelections[i][s] == option {
+		return
+	}
+	g.dataSelections[i][s] = option
+	g.dataSelects[i][s].SetSelectedIndex(option)
+	g.Refresh()
+	g.dataTracksChanged()
+}
+
+// returns the selected display transform, functions are drawn unchanged without transforms
+func (g *GraphCanvas) GetTransform() DisplayTransform {
+	if len(g.Config.Transforms) == 0 {
+		return ReflectivityTransform{}
+	}
+	return g.Config.Transforms[g.transform]
+}
+
+// selects the display transform with the given name, unknown names are ignored
+func (g *GraphCanvas) SetTransform(name string) {
+	i := slices.IndexFunc(g.Config.Transforms, func(t DisplayTransform) bool { return t.Name() == name })
+	if i == -1 || i == g.transform {
+		return
+	}
+	g.transform = i
+	if g.transformSelect != nil {
+		g.transformSelect.SetSelectedIndex(i)
+	}
+	g.Refresh()
+}
+
+// masks or unmasks the data point next to the clicked position (see function.Mask.TogglePoint)
+func (g *GraphCanvas) Tapped(event *fyne.PointEvent) {
+	nearest, distance := -1, maskTapDistance
+	for i, p := range g.dataPointPositions {
+		d := float32(math.Hypot(float64(p.position.X-event.Position.X), float64(p.position.Y-event.Position.Y)))
+		if d <= distance {
+			nearest, distance = i, d
+		}
+	}
+	if nearest == -1 || g.dataPointPositions[nearest].track >= len(g.loadedData) {
+		return
+	}
+
+	p := g.dataPointPositions[nearest]
+	track := g.loadedData[p.track]
+	if track.Mask == nil {
+		track.Mask = &function.Mask{}
+	}
+	track.Mask.TogglePoint(p.x)
+	g.Refresh()
+	g.dataTracksChanged()
+}
+
+// masks the x range of a data track
+func (g *GraphCanvas) MaskDataTrackRange(dataTrack *function.Function, minX, maxX float64) {
+	if dataTrack.Mask == nil {
+		dataTrack.Mask = &function.Mask{}
+	}
+	dataTrack.Mask.AddRange(minX, maxX)
+	g.Refresh()
+	g.dataTracksChanged()
+}
+
+// removes the mask of a data track
+func (g *GraphCanvas) ClearDataTrackMask(dataTrack *function.Function) {
+	dataTrack.Mask = nil
+	g.Refresh()
+	g.dataTracksChanged()
+}
+
+func (g *GraphCanvas) dataTracksChanged() {
+	if g.Config.OnDataTracksChanged != nil {
+		g.Config.OnDataTracksChanged()
+	}
+}
@@ -14,8 +14,20 @@ var (
 		colornames.Blue,
 		colornames.Brown,
 	}
+	// colors of the functions of a graph with channels (see GraphConfig.Channels)
+	ChannelColors = []color.Color{
+		&color.NRGBA{R: 0, G: 255, B: 0, A: 255},
+		&color.NRGBA{R: 255, G: 165, B: 0, A: 255},
+		&color.NRGBA{R: 0, G: 255, B: 255, A: 255},
+		&color.NRGBA{R: 255, G: 0, B: 255, A: 255},
+	}
 	RemoveButtonTopPadding float32 = 5
-	smallestGraphScope             = 1e-12
+	ChannelSelectWidth     float32 = 70
+	TransformSelectWidth   float32 = 90
+
+	// name of the selector of the channels (see GraphConfig.Channels)
+	CHANNEL_SELECTOR   = "channel"
+	smallestGraphScope = 1e-12
 )
 
 var (
@@ -43,16 +55,64 @@ var (
 	// color for the minor gridlines (for log scale)
 	gridMinorColor = &color.NRGBA{R: 128, G: 128, B: 128, A: 48}
 
+	// color of masked data points
+	maskedColor = &color.NRGBA{R: 128, G: 128, B: 128, A: 160}
+
 	// size of the points
 	pointRadius = float32(0.5)
+
+	// maximum distance in pixels between a click and the data point it masks
+	maskTapDistance = float32(6)
 )
 
 // GraphConfig configures the basic struct for a graph
 type GraphConfig struct {
 	Title        string
 	IsLog        bool
-	AdaptDraw    bool
 	Resolution   int
 	Functions    []*function.Function
 	DisplayRange *GraphRange
+
+	// optional names of the functions (e.g. spin channels), if set every data track
+	// can be assigned to one of the functions and the functions are drawn in the ChannelColors
+	// functions are repeated for every contrast, so function i belongs to channel i % len(Channels)
+	Channels []string
+
+	// optional additional selectors, every data track can be assigned to one of their options (e.g. contrasts)
+	Selectors []TrackSelector
+
+	// optional callback after a data track has been removed or assigned to another option of a selector
+	OnDataTracksChanged func()
+
+	// optional display transforms (e.g. R, R·q⁴, R/R_F, log R) selectable in the graph,
+	// without transforms the points are drawn unchanged
+	Transforms []DisplayTransform
+
+	// name of the transform selected initially, the first transform if empty
+	Transform string
+
+	// optional actions shown as buttons at the top left (e.g. stitching the data tracks)
+	Actions []GraphAction
+
+	// optional check boxes shown after the actions (e.g. showing the born approximation)
+	Toggles []GraphToggle
+}
+
+// GraphAction is a button of a graph calling OnTapped with the graph
+type GraphAction struct {
+	Name     string
+	OnTapped func(g *GraphCanvas)
+}
+
+// GraphToggle is a check box of a graph calling OnChanged with the graph and the new state
+type GraphToggle struct {
+	Name      string
+	Checked   bool
+	OnChanged func(g *GraphCanvas, checked bool)
+}
+
+// TrackSelector lets the user assign every data track of a graph to one of its options
+type TrackSelector struct {
+	Name    string
+	Options []string
 }
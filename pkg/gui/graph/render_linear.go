@@ -11,7 +11,8 @@ import (
 )
 
 // draw a linear graph
-func (r *GraphRenderer) DrawGraphLinear(scope *function.Scope, points, iPoints function.Points, pointColor color.Color, isDataSet bool) {
+// returns the positions of the drawn points
+func (r *GraphRenderer) DrawGraphLinear(scope *function.Scope, points, iPoints function.Points, pointColor color.Color, isDataSet bool) []fyne.Position {
 	// calc available space
 	availableWidth := r.size.Width - (1.5 * r.margin)
 	availableHeight := r.size.Height - (1.5 * r.margin)
@@ -57,6 +58,7 @@ func (r *GraphRenderer) DrawGraphLinear(scope *function.Scope, points, iPoints f
 	}
 
 	// draw data points
+	positions := make([]fyne.Position, 0, len(points))
 	for _, point := range points {
 		// scale x value to available width
 		x := float32((point.X-minX)/xRange) * availableWidth
@@ -75,7 +77,9 @@ func (r *GraphRenderer) DrawGraphLinear(scope *function.Scope, points, iPoints f
 			r.DrawError(xt, e1, e2, errorColor)
 		}
 		r.DrawPoint(xt, yt, pointColor)
+		positions = append(positions, fyne.NewPos(xt, yt))
 	}
+	return positions
 }
 
 // needed for pretty grids
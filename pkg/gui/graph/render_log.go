@@ -10,7 +10,8 @@ import (
 )
 
 // draw the graph in logarithmic scale
-func (r *GraphRenderer) DrawGraphLog(scope *function.Scope, points, iPoints function.Points, pointColor color.Color, isDataSet bool) {
+// returns the positions of the drawn points
+func (r *GraphRenderer) DrawGraphLog(scope *function.Scope, points, iPoints function.Points, pointColor color.Color, isDataSet bool) []fyne.Position {
 	// calc available space
 	availableWidth := r.size.Width - (1.5 * r.margin)
 	availableHeight := r.size.Height - (1.5 * r.margin)
@@ -62,6 +63,7 @@ func (r *GraphRenderer) DrawGraphLog(scope *function.Scope, points, iPoints func
 	}
 
 	// draw data points
+	positions := make([]fyne.Position, 0, len(points))
 	for _, point := range points {
 		// scale x and y values logarithmically
 		logX := math.Log10(point.X + xShift)
@@ -81,7 +83,9 @@ func (r *GraphRenderer) DrawGraphLog(scope *function.Scope, points, iPoints func
 			r.DrawError(xt, e1, e2, errorColor)
 		}
 		r.DrawPoint(xt, yt, pointColor)
+		positions = append(positions, fyne.NewPos(xt, yt))
 	}
+	return positions
 }
 
 func (r *GraphRenderer) DrawGridLog(scope *function.Scope) {
@@ -2,8 +2,8 @@ package graph
 
 import (
 	"image/color"
-	"math"
 	"physicsGUI/pkg/function"
+	"slices"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -89,31 +89,47 @@ func (r *GraphRenderer) Layout(size fyne.Size) {
 		}
 	}
 
-	// calculate the maximum scope
-	var scope = &function.Scope{
-		MinX: math.MaxFloat64,
-		MinY: math.MaxFloat64,
-		MaxX: -math.MaxFloat64,
-		MaxY: -math.MaxFloat64,
+	// transformed copies of the points, the data of the functions is not changed
+	transform := r.graph.GetTransform()
+	functionPoints := make([]function.Points, len(r.graph.functions))
+	for i, f := range r.graph.functions {
+		functionPoints[i] = transform.Transform(f.GetData().Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max))
+	}
+	// masked points of the data tracks are drawn separately
+	dataPoints := make([]function.Points, len(r.graph.loadedData))
+	maskedPoints := make([]function.Points, len(r.graph.loadedData))
+	for i, d := range r.graph.loadedData {
+		unmasked, masked := d.Mask.Split(d.GetData().Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max))
+		dataPoints[i] = transform.Transform(unmasked)
+		maskedPoints[i] = transform.Transform(masked)
 	}
-	funcCount := len(r.graph.functions)
-	var magicPoints []function.Points
-	if r.graph.Config.AdaptDraw {
-		magicPoints = make([]function.Points, funcCount+len(r.graph.loadedData))
-		for i, f := range r.graph.functions {
-			magicPoints[i] = f.GetData().Copy()
-			tempScope := magicPoints[i].Magie()
-			scope.CombineScope(&tempScope)
-		}
-		for i, f := range r.graph.loadedData {
-			l := i + funcCount
-			magicPoints[l] = f.GetData().Copy()
-			tempScope := magicPoints[l].Magie()
-			scope.CombineScope(&tempScope)
-		}
 
-	} else {
-		scope = function.GetMaximumScope(append(r.graph.functions, r.graph.loadedData...)...)
+	// select of the display transform and the action buttons at the top left
+	controlX := r.margin
+	if r.graph.transformSelect != nil {
+		r.graph.transformSelect.Resize(fyne.NewSize(TransformSelectWidth, r.graph.transformSelect.MinSize().Height))
+		r.graph.transformSelect.Move(fyne.NewPos(controlX, 0))
+		r.AddObject(r.graph.transformSelect)
+		controlX += TransformSelectWidth + RemoveButtonTopPadding
+	}
+	for _, button := range r.graph.actionButtons {
+		button.Resize(button.MinSize())
+		button.Move(fyne.NewPos(controlX, 0))
+		r.AddObject(button)
+		controlX += button.MinSize().Width + RemoveButtonTopPadding
+	}
+	for _, check := range r.graph.toggleChecks {
+		check.Resize(check.MinSize())
+		check.Move(fyne.NewPos(controlX, 0))
+		r.AddObject(check)
+		controlX += check.MinSize().Width + RemoveButtonTopPadding
+	}
+
+	// calculate the maximum scope
+	scope := pointsScope(slices.Concat(functionPoints, dataPoints, maskedPoints)...)
+	if scope == nil {
+		r.DrawErrorMessage("No data available")
+		return
 	}
 	if scope.MinX == scope.MaxX {
 		scope.MinX = scope.MinX - smallestGraphScope
@@ -124,84 +140,87 @@ func (r *GraphRenderer) Layout(size fyne.Size) {
 		scope.MaxY = scope.MaxY + smallestGraphScope
 	}
 
-	if scope == nil {
-		r.DrawErrorMessage("Scope error")
-		return
+	// Add Remove Buttons
+	r.DrawRemoveButtons()
+
+	// draw model lines, logarithmic values (log R) are drawn on linear axes
+	_, isLogTransform := transform.(LogTransform)
+	isLog := r.graph.Config.IsLog && !isLogTransform
+	drawGraph := r.DrawGraphLinear
+	if isLog {
+		drawGraph = r.DrawGraphLog
 	}
 
-	if (len(r.graph.functions) == 0 || r.graph.functions[0].GetDataCount() < 1) && len(r.graph.loadedData) == 0 {
-		r.DrawErrorMessage("No data available")
-		return
+	for i, points := range functionPoints {
+		drawGraph(scope, points, points, r.functionColor(i), false)
 	}
 
-	// Add Remove Buttons
-	r.DrawRemoveButtons()
+	// the positions of the data points are kept to mask them by clicking
+	r.graph.dataPointPositions = r.graph.dataPointPositions[:0]
+	for i, points := range dataPoints {
+		dataColor := DataTrackColors[i%len(DataTrackColors)]
+		r.recordDataPoints(i, points, drawGraph(scope, points, points, dataColor, true))
+		r.recordDataPoints(i, maskedPoints[i], drawGraph(scope, maskedPoints[i], maskedPoints[i], maskedColor, true))
+	}
 
-	// draw model lines
-	if r.graph.Config.IsLog {
-		for i, f := range r.graph.functions {
-			var points function.Points
-			if r.graph.Config.AdaptDraw {
-				points = magicPoints[i]
-			} else {
-				points = f.GetData().Copy()
-			}
-			r.DrawGraphLog(scope, points, points, pointColor, false)
-		}
-		for i, d := range r.graph.loadedData {
-			d.Range(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max)
-			var points function.Points
-			if r.graph.Config.AdaptDraw {
-				points = magicPoints[funcCount+i]
-			} else {
-				points = d.GetData().Copy()
-			}
-			dataColor := DataTrackColors[i%len(DataTrackColors)]
-			r.DrawGraphLog(scope, points, points, dataColor, true)
-		}
+	if isLog {
 		r.DrawGridLog(scope)
-		return
+	} else {
+		r.DrawGridLinear(scope)
 	}
+}
 
-	for i, f := range r.graph.functions {
-		var points function.Points
-		if r.graph.Config.AdaptDraw {
-			points = magicPoints[i]
-		} else {
-			points = f.GetData().Copy()
-		}
-		r.DrawGraphLinear(scope,
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			pointColor, false)
+// keeps the drawn positions of the points of a data track
+func (r *GraphRenderer) recordDataPoints(track int, points function.Points, positions []fyne.Position) {
+	for i, position := range positions {
+		r.graph.dataPointPositions = append(r.graph.dataPointPositions, dataPointPosition{track: track, x: points[i].X, position: position})
 	}
-	for i, d := range r.graph.loadedData {
-		var points function.Points
-		if r.graph.Config.AdaptDraw {
-			points = magicPoints[funcCount+i]
-		} else {
-			points = d.GetData().Copy()
-		}
-		dataColor := DataTrackColors[i%len(DataTrackColors)]
-		r.DrawGraphLinear(scope,
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			dataColor, true)
+}
+
+// returns the color of the function with index i, functions of graphs with channels get the channel colors,
+// graphs with several functions (e.g. intensity and born) get one color per function
+func (r *GraphRenderer) functionColor(i int) color.Color {
+	if channels := len(r.graph.Config.Channels); channels > 1 {
+		return ChannelColors[(i%channels)%len(ChannelColors)]
+	}
+	if len(r.graph.functions) > 1 {
+		return ChannelColors[i%len(ChannelColors)]
 	}
-	r.DrawGridLinear(scope)
+	return pointColor
 }
 
-// display remove buttons at the right border
+// display remove buttons at the right border with the selects and the legend entry of their data track
 func (r *GraphRenderer) DrawRemoveButtons() {
 	offsetY := float32(0)
 	startY := float32(0)
 	startX := r.size.Width - r.margin
-	for _, d := range r.graph.dataRemoveButtons {
+	for i, d := range r.graph.dataRemoveButtons {
 		offsetY += d.Size().Height + RemoveButtonTopPadding
 		for _, o := range d.Objects {
 			o.Move(fyne.NewPos(startX, startY+offsetY))
 			r.AddObject(o)
 		}
+
+		// selects (channel, contrast) left of the remove button
+		selectX := startX
+		for _, s := range r.graph.dataSelects[i] {
+			selectX -= ChannelSelectWidth + RemoveButtonTopPadding
+			s.Resize(fyne.NewSize(ChannelSelectWidth, d.Size().Height))
+			s.Move(fyne.NewPos(selectX, startY+offsetY))
+			r.AddObject(s)
+		}
+
+		// legend entry (name of the imported file) left of the selects
+		if label := r.graph.loadedData[i].Metadata.Label(); label != "" {
+			legend := &canvas.Text{
+				Text:     label,
+				Color:    DataTrackColors[i%len(DataTrackColors)],
+				TextSize: 12,
+			}
+			legendSize := legend.MinSize()
+			legend.Move(fyne.NewPos(selectX-legendSize.Width-RemoveButtonTopPadding, startY+offsetY+(d.Size().Height-legendSize.Height)/2))
+			r.AddObject(legend)
+		}
 	}
 }
 
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+)
+
+// DisplayTransform changes the points of a graph before they are drawn, the data of the functions is not changed
+type DisplayTransform interface {
+	// name shown in the select of the graph
+	Name() string
+	// returns transformed copies of the points with propagated errors, points without a valid value are dropped
+	Transform(points function.Points) function.Points
+}
+
+// ReflectivityTransform draws the points unchanged (R)
+type ReflectivityTransform struct{}
+
+func (ReflectivityTransform) Name() string {
+	return "R"
+}
+
+func (ReflectivityTransform) Transform(points function.Points) function.Points {
+	return points.Copy()
+}
+
+// Q4Transform multiplies the points with q^4 (R·q⁴), the fresnel decay of the reflectivity is removed
+type Q4Transform struct{}
+
+func (Q4Transform) Name() string {
+	return "R·q⁴"
+}
+
+func (Q4Transform) Transform(points function.Points) function.Points {
+	transformed := make(function.Points, len(points))
+	for i, p := range points {
+		q4 := p.X * p.X * p.X * p.X
+		transformed[i] = &function.Point{
+			X:          p.X,
+			Y:          p.Y * q4,
+			Error:      p.Error * q4,
+			Resolution: p.Resolution,
+		}
+	}
+	return transformed
+}
+
+// FresnelTransform divides the points by the fresnel reflectivity of the substrate (R/R_F)
+type FresnelTransform struct {
+	// returns the fresnel reflectivity at the q values
+	Fresnel func(qzaxis []float64) []float64
+}
+
+func (FresnelTransform) Name() string {
+	return "R/R_F"
+}
+
+func (t FresnelTransform) Transform(points function.Points) function.Points {
+	qzaxis := make([]float64, len(points))
+	for i, p := range points {
+		qzaxis[i] = p.X
+	}
+	fresnel := t.Fresnel(qzaxis)
+
+	transformed := make(function.Points, 0, len(points))
+	for i, p := range points {
+		if !(fresnel[i] > 0) {
+			continue
+		}
+		transformed = append(transformed, &function.Point{
+			X:          p.X,
+			Y:          p.Y / fresnel[i],
+			Error:      p.Error / fresnel[i],
+			Resolution: p.Resolution,
+		})
+	}
+	return transformed
+}
+
+// LogTransform takes the decadic logarithm of the points (log R), they are drawn on linear axes
+// the error is propagated linearly (σ / (R ln 10)), points with a non positive value are dropped
+type LogTransform struct{}
+
+func (LogTransform) Name() string {
+	return "log R"
+}
+
+func (LogTransform) Transform(points function.Points) function.Points {
+	transformed := make(function.Points, 0, len(points))
+	for _, p := range points {
+		if p.Y <= 0 {
+			continue
+		}
+		transformed = append(transformed, &function.Point{
+			X:          p.X,
+			Y:          math.Log10(p.Y),
+			Error:      p.Error / (p.Y * math.Ln10),
+			Resolution: p.Resolution,
+		})
+	}
+	return transformed
+}
+
+// ReflectivityTransforms returns the display transforms of reflectivity graphs (R, R·q⁴, R/R_F, log R)
+// fresnel returns the fresnel reflectivity of the current substrate at the q values
+func ReflectivityTransforms(fresnel func(qzaxis []float64) []float64) []DisplayTransform {
+	return []DisplayTransform{ReflectivityTransform{}, Q4Transform{}, FresnelTransform{Fresnel: fresnel}, LogTransform{}}
+}
+
+// returns the scope of the points, nil if there are none
+func pointsScope(points ...function.Points) *function.Scope {
+	var scope *function.Scope
+	for _, p := range points {
+		if len(p) == 0 {
+			continue
+		}
+		minX, maxX, minY, maxY := p.MinMaxXY()
+		if scope == nil {
+			scope = &function.Scope{MinX: minX, MaxX: maxX, MinY: minY, MaxY: maxY}
+		} else {
+			scope.CombineScope(&function.Scope{MinX: minX, MaxX: maxX, MinY: minY, MaxY: maxY})
+		}
+	}
+	return scope
+}
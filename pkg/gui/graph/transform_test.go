@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"testing"
+)
+
+func TestReflectivityTransforms(t *testing.T) {
+	points := function.Points{
+		{X: 0.1, Y: 0.01, Error: 0.001, Resolution: 0.002},
+		{X: 0.2, Y: 0, Error: 0.001},
+		{X: 0.5, Y: -1e-6, Error: 1e-6},
+	}
+	// constant fresnel reflectivity of 0.1, no fresnel reflectivity at 0.5
+	fresnel := func(qzaxis []float64) []float64 {
+		r := make([]float64, len(qzaxis))
+		for i, q := range qzaxis {
+			if q < 0.5 {
+				r[i] = 0.1
+			}
+		}
+		return r
+	}
+
+	for _, c := range []struct {
+		transform DisplayTransform
+		expected  function.Points
+	}{
+		{ReflectivityTransform{}, points},
+		{Q4Transform{}, function.Points{
+			{X: 0.1, Y: 1e-6, Error: 1e-7, Resolution: 0.002},
+			{X: 0.2, Y: 0, Error: 1.6e-6},
+			{X: 0.5, Y: -6.25e-8, Error: 6.25e-8},
+		}},
+		{FresnelTransform{Fresnel: fresnel}, function.Points{
+			{X: 0.1, Y: 0.1, Error: 0.01, Resolution: 0.002},
+			{X: 0.2, Y: 0, Error: 0.01},
+		}},
+		// points with R <= 0 have no logarithm
+		{LogTransform{}, function.Points{
+			{X: 0.1, Y: -2, Error: 0.1 / math.Ln10, Resolution: 0.002},
+		}},
+	} {
+		transformed := c.transform.Transform(points)
+		if len(transformed) != len(c.expected) {
+			t.Errorf("%s: expected %d points got %d", c.transform.Name(), len(c.expected), len(transformed))
+			continue
+		}
+		for i, p := range transformed {
+			e := c.expected[i]
+			if p.X != e.X || p.Resolution != e.Resolution || math.Abs(p.Y-e.Y) > 1e-12 || math.Abs(p.Error-e.Error) > 1e-12 {
+				t.Errorf("%s: expected %v got %v", c.transform.Name(), *e, *p)
+			}
+		}
+	}
+
+	// the points of the functions are not changed
+	if points[0].Y != 0.01 || points[0].Error != 0.001 {
+		t.Errorf("transform changed the original points: %v", *points[0])
+	}
+}
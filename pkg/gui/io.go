@@ -7,7 +7,6 @@ import (
 	io2 "io"
 	"os"
 	"physicsGUI/pkg/io"
-	"strings"
 )
 
 func loadFileChooser() {
@@ -35,18 +34,8 @@ func fileLoader(reader fyne.URIReadCloser, err error) {
 		return
 	}
 
-	var config *io.ConfigInformation
-	var decodeErr error
-
-	// decode loaded bytes
-	if strings.EqualFold(".xml", uri.Extension()) {
-		config, decodeErr = io.DecodeXMLFromBytes(data)
-	} else if strings.EqualFold(".json", uri.Extension()) {
-		config, decodeErr = io.DecodeJSONFromBytes(data)
-	} else {
-		// if format is not xml or json try binary encoding
-		config, decodeErr = io.DecodeGOBFromBytes(data)
-	}
+	// decode loaded bytes with the format of the extension or content, binary encoding otherwise
+	config, decodeErr := io.FindProjectFormat(uri.Name(), data).Decode(data)
 	if decodeErr != nil {
 		dialog.ShowError(decodeErr, MainWindow)
 		return
@@ -92,16 +81,9 @@ func fileSaver(writer fyne.URIWriteCloser, err error) {
 		return
 	}
 
-	var data []byte
-	var eError error
+	// encode with the format of the extension, binary encoding otherwise
 	uri := writer.URI()
-	if strings.EqualFold(".xml", uri.Extension()) {
-		data, eError = io.EncodeXMLToBytes(config)
-	} else if strings.EqualFold(".json", uri.Extension()) {
-		data, eError = io.EncodeJSONToBytes(config)
-	} else {
-		data, eError = io.EncodeGOBToBytes(config)
-	}
+	data, eError := io.FindProjectFormat(uri.Name(), nil).Encode(config)
 	if eError != nil {
 		dialog.ShowError(eError, MainWindow)
 		return
@@ -128,20 +110,11 @@ func fileExporter(writer fyne.URIWriteCloser, err error) {
 		return // user abort
 	}
 
-	exportInfo := CreateExport()
+	exportInfo := io.ExportInformation{Graphs: CreateExport(), Datasets: CreateORTExport()}
 
-	var data []byte
-	var eError error
+	// export with the format of the extension, csv otherwise
 	uri := writer.URI()
-	if strings.EqualFold(".xml", uri.Extension()) {
-		data, eError = io.ExportXMLToFile(exportInfo)
-	} else if strings.EqualFold(".json", uri.Extension()) {
-		data, eError = io.ExportJSONToFile(exportInfo)
-	} else if strings.EqualFold(".csv", uri.Extension()) {
-		data, eError = io.ExportCSVToFile(exportInfo)
-	} else { // default point export format?
-		data, eError = io.ExportDefaultToFile(exportInfo)
-	}
+	data, eError := io.FindExportFormat(uri.Name()).Export(exportInfo)
 
 	if eError != nil {
 		dialog.ShowError(eError, MainWindow)
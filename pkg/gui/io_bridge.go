@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"physicsGUI/pkg/data"
 	"physicsGUI/pkg/function"
 	"physicsGUI/pkg/gui/param"
 	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -72,6 +75,13 @@ func getProgramPlotKeys() []string {
 }
 
 func LoadConfig(config *io.ConfigInformation, forceLoad bool) error {
+	// the structure defines which parameters exist, files of older versions do not have it
+	if config.Structure != nil {
+		if err := loadStructureInformation(config.Structure); err != nil {
+			return err
+		}
+	}
+
 	// check parameter version indicator skipped in force Load
 	if !forceLoad && !slices.Equal(makeVersionCheckSum(getProgramParameterKeys()), config.ParameterVersionIndicator) {
 		return differentParameterVersionError
@@ -83,6 +93,12 @@ func LoadConfig(config *io.ConfigInformation, forceLoad bool) error {
 		return err
 	}
 
+	// load settings
+	err = loadSettingInformation(config.Settings)
+	if err != nil {
+		return err
+	}
+
 	// check plot version indicator skipped in force Load
 	if !forceLoad && !slices.Equal(makeVersionCheckSum(getProgramPlotKeys()), config.PlotVersionIndicator) {
 		return differentPlotVersionError
@@ -193,12 +209,49 @@ func loadPlotInformation(paramInfo []io.PlotInformation) error {
 			fcn := function.NewFunction(information.DataTracks[i].Points)
 			scopeCopy := information.DataTracks[i].Scope
 			fcn.Scope = &scopeCopy
+			fcn.Metadata = information.DataTracks[i].Metadata
+			fcn.Mask = information.DataTracks[i].Mask
 			graphMap[information.Name].AddDataTrack(fcn)
+			graphMap[information.Name].SetDataTrackChannel(fcn, information.DataTracks[i].Channel)
+			graphMap[information.Name].SetDataTrackSelection(fcn, contrastSelector, information.DataTracks[i].Contrast)
 		}
 	}
 	return nil
 }
 
+// returns the layer stack, spline knots and contrasts of the current model
+func createStructureInformation() *io.StructureInformation {
+	return &io.StructureInformation{
+		Layers:       layerStack.Layers,
+		RepeatLayers: layerStack.RepeatLayers,
+		Magnetic:     layerStack.Magnetic,
+		SplineKnots:  splineProfile.Knots,
+		Contrasts:    modelDefinition.Contrasts,
+	}
+}
+
+// rebuilds the layer stack and spline profile of a project, the contrasts are defined by the model definition
+func loadStructureInformation(structure *io.StructureInformation) error {
+	if !slices.Equal(structure.Contrasts, modelDefinition.Contrasts) {
+		return fmt.Errorf("project contrasts %v do not match the contrasts %v of the model definition", structure.Contrasts, modelDefinition.Contrasts)
+	}
+	if structure.Layers < 0 || structure.RepeatLayers < 0 {
+		return fmt.Errorf("invalid layer stack of %d layers and %d repeat layers", structure.Layers, structure.RepeatLayers)
+	}
+	if structure.SplineKnots < physics.MIN_SPLINE_KNOTS {
+		return fmt.Errorf("%d spline knots, at least %d needed", structure.SplineKnots, physics.MIN_SPLINE_KNOTS)
+	}
+
+	stack := physics.LayerStack{Layers: structure.Layers, Magnetic: structure.Magnetic, RepeatLayers: structure.RepeatLayers}
+	if stack != *layerStack {
+		setLayerStack(layerParamsContainer, &stack)
+	}
+	if structure.SplineKnots != splineProfile.Knots {
+		setSplineProfile(splineParamsContainer, physics.NewSplineProfile(structure.SplineKnots))
+	}
+	return nil
+}
+
 func CreateConfig() (*io.ConfigInformation, error) {
 
 	// create ParameterInformation
@@ -213,11 +266,19 @@ func CreateConfig() (*io.ConfigInformation, error) {
 		return nil, err
 	}
 
+	// create SettingInformation
+	settings, err := createSettingInformation()
+	if err != nil {
+		return nil, err
+	}
+
 	return &io.ConfigInformation{
 		PlotVersionIndicator:      makeVersionCheckSum(getProgramPlotKeys()),
 		Plot:                      plot,
 		ParameterVersionIndicator: makeVersionCheckSum(getProgramParameterKeys()),
 		Parameter:                 parameters,
+		Settings:                  settings,
+		Structure:                 createStructureInformation(),
 	}, nil
 }
 
@@ -351,8 +412,12 @@ func createPlotInformation() ([]io.PlotInformation, error) {
 			scopeCopy := *dataTracks[i].Scope // this should copy the struct
 
 			funcInfo := io.FunctionInformation{
-				Points: dataTracks[i].GetData(),
-				Scope:  scopeCopy,
+				Points:   dataTracks[i].GetData(),
+				Scope:    scopeCopy,
+				Channel:  plot.GetDataTrackChannel(dataTracks[i]),
+				Contrast: plot.GetDataTrackSelection(dataTracks[i], contrastSelector),
+				Metadata: dataTracks[i].Metadata.Copy(),
+				Mask:     dataTracks[i].Mask.Copy(),
 			}
 			funcInfos = append(funcInfos, funcInfo)
 		}
@@ -386,3 +451,56 @@ func CreateExport() []io.PointsExport {
 
 	return res
 }
+
+// returns the metadata of a data track with the keys of the ORSO header
+func ortMetadata(metadata *function.Metadata) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	values := map[string]string{
+		data.METADATA_SAMPLE:     metadata.Sample,
+		data.METADATA_INSTRUMENT: metadata.Instrument,
+		data.METADATA_UNITS:      metadata.Units,
+	}
+	// 0 is an unknown temperature
+	if metadata.Temperature != 0 {
+		values[data.METADATA_TEMPERATURE] = strconv.FormatFloat(metadata.Temperature, 'g', -1, 64)
+	}
+	for _, key := range []string{data.METADATA_PROBE, data.METADATA_OWNER} {
+		values[key], _ = metadata.Get(key)
+	}
+	return values
+}
+
+// returns the data tracks and the reflectivity curves of all graphs as datasets of an ORSO file
+// data tracks keep the metadata of their import, model curves are named after their graph, function and contrast
+func CreateORTExport() []io.ORTDataset {
+	res := make([]io.ORTDataset, 0)
+
+	for _, g := range modelDefinition.Graphs {
+		plot, ok := graphMap[g.Id]
+		if !ok || !slices.ContainsFunc(g.Functions, isReflectivityFunction) {
+			continue
+		}
+
+		for i, track := range plot.GetDataTracks() {
+			name := fmt.Sprintf("%s data %d", g.Id, i+1)
+			if track.Metadata != nil {
+				name = fmt.Sprintf("%s %s", name, track.Metadata.Name)
+			}
+			res = append(res, io.ORTDataset{Name: name, Metadata: ortMetadata(track.Metadata), Points: track.GetData()})
+		}
+
+		for c := range contrastCount() {
+			for _, identifier := range g.Functions {
+				points := functionMap[functionKey(g.Id, identifier, c)].GetData()
+				if !isReflectivityFunction(identifier) || len(points) == 0 {
+					continue
+				}
+				res = append(res, io.ORTDataset{Name: functionKey(g.Id, identifier, c), Points: points})
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,52 @@
+package gui
+
+import (
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a project with 4 layers and a repeat unit restores its layer stack before the parameters are loaded
+func TestProjectStructureRoundTrip(t *testing.T) {
+	TestSetup(t)
+	original := *layerStack
+	defer setLayerStack(layerParamsContainer, &original)
+
+	saved := physics.LayerStack{Layers: 4, RepeatLayers: 2}
+	setLayerStack(layerParamsContainer, &saved)
+	assert.NoError(t, param.SetFloat(physics.THICKNESS_GROUP, "Thickness 4", 42))
+	assert.NoError(t, param.SetFloat(physics.REPEAT_GROUP, "Repeat Thickness 2", 17))
+
+	config, err := CreateConfig()
+	assert.NoError(t, err)
+	encoded, err := io.EncodeJSONToBytes(config)
+	assert.NoError(t, err)
+	decoded, err := io.DecodeJSONFromBytes(encoded)
+	assert.NoError(t, err)
+
+	// the parameters of the saved stack do not exist in the default stack
+	setLayerStack(layerParamsContainer, &original)
+	_, err = param.GetFloat(physics.THICKNESS_GROUP, "Thickness 4")
+	assert.Error(t, err)
+
+	assert.NoError(t, LoadConfig(decoded, false))
+	assert.Equal(t, saved, *layerStack)
+
+	thickness, err := param.GetFloat(physics.THICKNESS_GROUP, "Thickness 4")
+	assert.NoError(t, err)
+	assert.Equal(t, 42.0, thickness)
+	repeatThickness, err := param.GetFloat(physics.REPEAT_GROUP, "Repeat Thickness 2")
+	assert.NoError(t, err)
+	assert.Equal(t, 17.0, repeatThickness)
+}
+
+// projects of another model definition are rejected
+func TestProjectStructureContrasts(t *testing.T) {
+	TestSetup(t)
+	structure := createStructureInformation()
+	structure.Contrasts = append(structure.Contrasts, "D2O")
+	assert.Error(t, loadStructureInformation(structure))
+}
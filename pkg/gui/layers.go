@@ -0,0 +1,160 @@
+package gui
+
+import (
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/trigger"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// order of the layer parameter groups in the gui
+var layerGroupOrder = []string{physics.EDEN_GROUP, physics.ROUGHNESS_GROUP, physics.THICKNESS_GROUP, physics.ABSORPTION_GROUP, physics.MAGNETIC_GROUP, physics.ANGLE_GROUP, physics.REPEAT_GROUP}
+
+// name of the int parameter with the number of periods of the repeat unit (group physics.REPEAT_GROUP)
+const repetitionsName = "Repetitions"
+
+// input of the number of periods, created once and shown with the repeat unit
+var repetitionsObject fyne.CanvasObject
+
+// switch of the magnetic layers, follows the layer stack when a project is loaded
+var magneticCheck *widget.Check
+
+// creates the buttons for adding and removing layers and the switch for magnetic layers of the layer stack
+func createLayerButtons(layerParams *fyne.Container) *fyne.Container {
+	// changes a copy of the current stack
+	changeStack := func(change func(s *physics.LayerStack)) {
+		newStack := *layerStack
+		change(&newStack)
+		setLayerStack(layerParams, &newStack)
+	}
+
+	btnAdd := widget.NewButtonWithIcon("Add Layer", theme.ContentAddIcon(), func() {
+		changeStack(func(s *physics.LayerStack) { s.Layers++ })
+	})
+	btnRemove := widget.NewButtonWithIcon("Remove Layer", theme.ContentRemoveIcon(), func() {
+		if layerStack.Layers > 0 {
+			changeStack(func(s *physics.LayerStack) { s.Layers-- })
+		}
+	})
+
+	// layers of the repeat unit (superlattice) above the substrate
+	btnAddRepeat := widget.NewButtonWithIcon("Add Repeat Layer", theme.ContentAddIcon(), func() {
+		changeStack(func(s *physics.LayerStack) { s.RepeatLayers++ })
+	})
+	btnRemoveRepeat := widget.NewButtonWithIcon("Remove Repeat Layer", theme.ContentRemoveIcon(), func() {
+		if layerStack.RepeatLayers > 0 {
+			changeStack(func(s *physics.LayerStack) { s.RepeatLayers-- })
+		}
+	})
+
+	// magnetic sld and angle for polarised neutron reflectivity
+	magneticCheck = widget.NewCheck("Magnetic", nil)
+	magneticCheck.SetChecked(layerStack.Magnetic)
+	magneticCheck.OnChanged = func(magnetic bool) {
+		if magnetic != layerStack.Magnetic {
+			changeStack(func(s *physics.LayerStack) { s.Magnetic = magnetic })
+		}
+	}
+
+	return container.NewHBox(btnAdd, btnRemove, btnAddRepeat, btnRemoveRepeat, magneticCheck)
+}
+
+// returns the number of periods of the repeat unit
+func repetitions() int {
+	n, err := param.GetInt(physics.REPEAT_GROUP, repetitionsName)
+	if err != nil {
+		return 0
+	}
+	return max(n, 0)
+}
+
+// creates the parameters of the layer stack and adds them to the container grouped by parameter group
+func buildLayerParams(layerParams *fyne.Container) {
+	groups := make(map[string][]fyne.CanvasObject)
+	for _, spec := range layerStack.Parameters() {
+		canvasObject := createModelParameter(spec, true)
+		// eden parameters defined by a formula show the density instead
+		if m := findMaterial(spec.Group, spec.Name); m != nil {
+			canvasObject = createMaterialParameter(spec, m)
+		}
+		// local parameters are shown in the rows of the contrasts
+		if !isLocalParameter(spec.Group, spec.Name) {
+			groups[spec.Group] = append(groups[spec.Group], canvasObject)
+		}
+	}
+
+	// the number of periods is an int parameter, it is kept when the stack changes
+	if repetitionsObject == nil {
+		repetitionsObject, _ = param.Int(physics.REPEAT_GROUP, repetitionsName, modelDefinition.Repetitions)
+	}
+	if layerStack.RepeatLayers > 0 {
+		groups[physics.REPEAT_GROUP] = append([]fyne.CanvasObject{repetitionsObject}, groups[physics.REPEAT_GROUP]...)
+	}
+
+	rows := make([]fyne.CanvasObject, 0, len(layerGroupOrder))
+	for _, group := range layerGroupOrder {
+		if len(groups[group]) > 0 {
+			rows = append(rows, container.NewGridWithColumns(4, groups[group]...))
+		}
+	}
+
+	layerParams.Objects = rows
+	layerParams.Refresh()
+}
+
+// replaces the layer stack (number of layers, magnetic, repeat unit) and rebuilds the layer parameters
+// values, limits and fit flags of parameters which still exist are kept
+func setLayerStack(layerParams *fyne.Container, newStack *physics.LayerStack) {
+	// keep the current state of the layer parameters
+	snapshot, err := createParameterInformation()
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+
+	// the substrate interface keeps its roughness when layers are added or removed
+	oldSubstrateRoughness := layerStack.RoughnessName(layerStack.Layers)
+	newSubstrateRoughness := newStack.RoughnessName(newStack.Layers)
+
+	names := make(map[string]bool)
+	for _, spec := range newStack.Parameters() {
+		names[spec.Group+"/"+spec.Name] = true
+	}
+
+	kept := make([]io.ParameterInformation, 0, len(snapshot))
+	for _, info := range snapshot {
+		if !slices.Contains(layerGroupOrder, info.Group) || info.Name == repetitionsName {
+			continue
+		}
+		if info.Group == physics.ROUGHNESS_GROUP && info.Name == oldSubstrateRoughness {
+			info.Name = newSubstrateRoughness
+		}
+		if names[info.Group+"/"+info.Name] {
+			kept = append(kept, info)
+		}
+	}
+
+	// recreate the layer parameters
+	for _, group := range layerGroupOrder {
+		param.RemoveFloatGroup(group)
+	}
+	layerStack = newStack
+	buildLayerParams(layerParams)
+	updateGraphVisibility()
+	if magneticCheck != nil {
+		magneticCheck.SetChecked(layerStack.Magnetic)
+	}
+
+	if err := loadParameterInformation(kept); err != nil {
+		dialog.ShowError(err, MainWindow)
+	}
+
+	trigger.Recalc()
+}
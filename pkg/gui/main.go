@@ -1,7 +1,6 @@
 package gui
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,12 +15,16 @@ import (
 	"physicsGUI/pkg/minimizer"
 	"physicsGUI/pkg/physics"
 	"physicsGUI/pkg/trigger"
+	"slices"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 )
 
 var (
@@ -31,6 +34,24 @@ var (
 
 	functionMap = make(map[string]*function.Function)
 	graphMap    = make(map[string]*graph.GraphCanvas)
+
+	// container of all graphs, refreshed when graphs are shown or hidden
+	graphGrid *fyne.Container
+
+	// ids of the graphs showing the kinematic intensity of their "born" function, toggled in the graph
+	bornGraphs = make(map[string]bool)
+
+	// layer model between ambient medium and substrate, defines the eden, thickness, roughness and absorption parameters
+	layerStack = newModelLayerStack(modelDefinition)
+
+	// free-form profile used instead of the layer stack in the spline profile mode
+	splineProfile = physics.NewSplineProfile(modelDefinition.SplineKnots)
+
+	// containers of the layer and spline parameters, rebuilt when the structure changes (see loadStructureInformation)
+	layerParamsContainer, splineParamsContainer *fyne.Container
+
+	// angle of incidence of the last imported wavelength columns
+	lastImportAngle = 0.0
 )
 
 // adaption should not be necessary here
@@ -56,11 +77,13 @@ func onDrop(position fyne.Position, uri []fyne.URI) {
 					return
 				}
 
-				if points := addDataset(rc, v, nil); points != nil {
-					newFunction := function.NewFunction(points)
+				addDataset(rc, v, nil, func(dataset *data.Dataset) {
+					newFunction := function.NewFunction(dataset.Points)
+					newFunction.Metadata = dataset.NewMetadata(v.Path())
 					graphMap[mapIdentifier].AddDataTrack(newFunction)
-					physics.AlterQZAxis(graphMap[mapIdentifier].GetDataTracks(), mapIdentifier)
-				}
+					// the intensities of the graph are calculated on the q values of its data
+					trigger.Recalc()
+				})
 			}
 			return
 		}
@@ -68,14 +91,16 @@ func onDrop(position fyne.Position, uri []fyne.URI) {
 }
 
 // adaption should not be necessary here
-// parses a given file into a dataset
-func addDataset(reader io.ReadCloser, uri fyne.URI, err error) function.Points {
+// parses a given file into datasets with the data format registered for it (see data.FindFormat),
+// the first column of files without units is converted to qz with the selected import columns
+// onImport is called with every dataset of the file
+func addDataset(reader io.ReadCloser, uri fyne.URI, err error, onImport func(dataset *data.Dataset)) {
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
-		return nil
+		return
 	}
 	if reader == nil {
-		return nil // user canceled
+		return // user canceled
 	}
 	defer func() {
 		if err := reader.Close(); err != nil {
@@ -87,30 +112,85 @@ func addDataset(reader io.ReadCloser, uri fyne.URI, err error) function.Points {
 	bytes, err := io.ReadAll(reader)
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
-		return nil
+		return
 	}
 
 	// get filename
 	filename := filepath.Base(uri.Name())
 
-	// handle import
-	points, err := data.Parse(bytes)
-	if err != nil {
-		dialog.ShowError(err, MainWindow)
-		return nil
+	// the format is selected by the content and the extension of the file
+	format := data.FindFormat(filename, bytes)
+
+	importFormat := func(columns data.Columns) {
+		options := data.ImportOptions{Parse: data.DefaultParseOptions(), Columns: columns}
+		options.Parse.Delimiter = importDelimiter
+
+		datasets, err := format.Import(bytes, options)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s import: %v", format.Name, err), MainWindow)
+			return
+		}
+
+		// show success message
+		dialog.ShowInformation("Import successful",
+			fmt.Sprintf("File '%s' imported (%s, %d datasets)", filename, format.Name, len(datasets)),
+			MainWindow)
+
+		for _, dataset := range datasets {
+			onImport(dataset)
+		}
+	}
+
+	// formats without units use the import columns
+	columns := data.Columns{X: data.QZ_ANGSTROM}
+	if format.UsesColumns {
+		columns = data.Columns{X: importColumns, Wavelength: importWavelength(), Angle: lastImportAngle}
 	}
+	if !columns.NeedsWavelength() && !columns.NeedsAngle() {
+		importFormat(columns)
+		return
+	}
+	showColumnsDialog(columns, importFormat)
+}
 
-	if len(points) == 0 {
-		dialog.ShowError(errors.New("no data"), MainWindow)
-		return nil
+// asks for the wavelength (angle columns) or the angle of incidence (wavelength columns) of an import
+func showColumnsDialog(columns data.Columns, onConfirm func(columns data.Columns)) {
+	label, value := "Wavelength (Å)", columns.Wavelength
+	if columns.NeedsAngle() {
+		label, value = "Angle θ (deg)", columns.Angle
 	}
 
-	// show success message
-	dialog.ShowInformation("Import successful",
-		fmt.Sprintf("File '%s' imported", filename),
-		MainWindow)
+	entry := widget.NewEntry()
+	entry.SetText(strconv.FormatFloat(value, 'g', -1, 64))
 
-	return points
+	dialog.ShowForm(fmt.Sprintf("Import %s", columns.X), "Import", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem(label, entry)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(entry.Text), 64)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid value '%s': %v", entry.Text, err), MainWindow)
+				return
+			}
+			if columns.NeedsAngle() {
+				columns.Angle = value
+				lastImportAngle = value
+			} else {
+				columns.Wavelength = value
+			}
+			onConfirm(columns)
+		}, MainWindow)
+}
+
+// returns the wavelength of the general parameters used to convert imported angles
+func importWavelength() float64 {
+	wavelength, err := param.GetFloat("general", "wavelength")
+	if err != nil {
+		return 0
+	}
+	return wavelength
 }
 
 // adaption should not be necessary here
@@ -121,6 +201,11 @@ func createFileMenu() *fyne.Menu {
 	return fyne.NewMenu("File", mnLoad, mnSave, mnExport)
 }
 
+func createToolsMenu() *fyne.Menu {
+	mnCalculator := fyne.NewMenuItem("SLD Calculator", showMaterialCalculator)
+	return fyne.NewMenu("Tools", mnCalculator)
+}
+
 // adaption should not be necessary here
 // mainWindow builds and renders the main GUI content, it will show and run the main window
 func mainWindow() {
@@ -130,6 +215,8 @@ func mainWindow() {
 		container.NewVBox(
 			container.NewHBox(
 				NewMinimizerControlPanel().Widget(),
+				helper.CreateSeparator(),
+				registerSettings(),
 			),
 			helper.CreateSeparator(),
 		), // top
@@ -149,6 +236,7 @@ func mainWindow() {
 	MainWindow.SetMainMenu(fyne.NewMainMenu(
 		fyne.NewMenu("Program"),
 		createFileMenu(),
+		createToolsMenu(),
 	))
 	MainWindow.Resize(fyne.NewSize(1000, 500))
 	MainWindow.SetContent(content)
@@ -159,38 +247,21 @@ func mainWindow() {
 
 //!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!! adapt everything from here !!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
 
+// the parameters, functions and graphs are defined by the model definition (default_model.yaml or the file passed with -model)
+// see model.go for the physics functions which can be referenced there
+
 // this is also the place where you need to pass:
 // all current parameters and all experimental data tracks
 func (controlPanel *MinimizerControlPanel) minimizerProblemSetup() error {
-	// get parameters + experimental data and put them into minimize()
-	edens := param.GetFloatGroup("eden")
-
-	e1 := edens.GetParam("Eden a")
-	e2 := edens.GetParam("Eden 1")
-	e3 := edens.GetParam("Eden 2")
-	e4 := edens.GetParam("Eden b")
-
-	// get roughness parameters
-	roughness := param.GetFloatGroup("rough")
-
-	r1 := roughness.GetParam("Roughness a/1")
-	r2 := roughness.GetParam("Roughness 1/2")
-	r3 := roughness.GetParam("Roughness 2/b")
-
-	// get thickness parameters
-	thickness := param.GetFloatGroup("thick")
-
-	t1 := thickness.GetParam("Thickness 1")
-	t2 := thickness.GetParam("Thickness 2")
-
-	// get general parameters
-	general := param.GetFloatGroup("general")
-
-	delta := general.GetParam("deltaq")
-	background := general.GetParam("background")
-	scaling := general.GetParam("scaling")
+	// get the parameters of the layer stack (eden, thickness, roughness, absorption) followed by the general parameters
+	// shared parameters are passed once, local parameters once per contrast
+	layout, err := newFitLayout()
+	if err != nil {
+		return err
+	}
+	currentFitLayout = layout
 
-	if err := controlPanel.minimize(e1, e2, e3, e4, t1, t2, r1, r2, r3, delta, background, scaling); err != nil {
+	if err := controlPanel.minimize(layout.parameters...); err != nil {
 		fmt.Println("Error while minimizing:", err)
 		return err
 	}
@@ -200,127 +271,218 @@ func (controlPanel *MinimizerControlPanel) minimizerProblemSetup() error {
 // the penalty function defines the error we minimize with minuit
 // !the order of the parameters needs to fit
 func penaltyFunction(fcn *minimizer.MinuitFunction, params []float64) float64 {
-	paramCount := 12
-	if len(params) != paramCount {
-		dialog.ShowError(fmt.Errorf("penaltyFunction has %d parameters but expects %d", len(params), paramCount), MainWindow)
-		return math.MaxFloat64
+	//sort the parameters, every contrast has its own model instance
+	//errors are not shown in dialogs, the penalty is called for every step of the minimizer
+	states := make([]*modelState, contrastCount())
+	for c := range states {
+		values, err := currentFitLayout.values(params, c)
+		if err != nil {
+			log.Println("penaltyFunction:", err)
+			return math.MaxFloat64
+		}
+		state, err := newModelState(values)
+		if err != nil {
+			log.Println("penaltyFunction:", err)
+			return math.MaxFloat64
+		}
+		states[c] = state
 	}
 
-	//sort the parameters
-	edenErr := params[0:4]
-	dErr := params[4:6]
-	sigmaErr := params[6:9]
-	deltaErr := params[9]
-	backgroundErr := params[10]
-	scalingErr := params[11]
-
 	log.Println("params", params)
 
-	//precalculation for intensities
-	edenPoints, err := physics.GetEdensities(edenErr, dErr, sigmaErr)
-	if err != nil {
-		fmt.Println("Error while calculating edensities:", err)
-		return math.MaxFloat64
-	}
-
-	//intensity calculation itself
-	intensityPoints := physics.CalculateIntensityPoints(edenPoints, deltaErr, &physics.IntensityOptions{
-		Background: backgroundErr,
-		Scaling:    scalingErr,
-	})
-
-	experimentalData := graphMap["intensity"].GetDataTracks()
-	dataTracks := make([]function.Points, len(experimentalData))
-	for i, dataTrack := range experimentalData {
-		dataTracks[i] = dataTrack.GetData()
-	}
+	//every data track is compared to the function of the contrast it is assigned to (e.g. a spin channel)
+	//the combined error of all contrasts is minimized, summed in a fixed order
+	diff := 0.0
+	for _, t := range fitTargets() {
+		for _, dataTrack := range t.tracks {
+			// masked points are excluded from the fit
+			trackPoints := dataTrack.GetUnmaskedData()
+
+			//intensity calculation itself, on the q values and with the resolution of the data track
+			intensityPoints, err := modelFunctions[t.target.identifier](states[t.target.contrast].withDataSets([]function.Points{trackPoints}))
+			if err != nil {
+				fmt.Println("Error while calculating intensities:", err)
+				return math.MaxFloat64
+			}
 
-	//penalty calculation
-	diff, err := physics.Sim2SigRMS(dataTracks, intensityPoints)
-	if err != nil {
-		dialog.ShowError(err, MainWindow)
+			//penalty calculation
+			trackDiff, err := physics.CalculateCost(costFunction, []function.Points{trackPoints}, intensityPoints)
+			if err != nil {
+				log.Println("penaltyFunction:", err)
+				return math.MaxFloat64
+			}
+			diff += trackDiff
+		}
 	}
 
 	return diff
 }
 
 // register functions which can be used for graph plotting
-// this is the place to add function plots which are shown in graphs
+// every function referenced by a graph of the model definition is registered once per graph (see functionKey)
 func registerFunctions() {
 	//a function needs to be added to the functionMap using a unique identifier so we can further handle it
 	//interpolation mode can be ignored
-	functionMap["intensity"] = function.NewEmptyFunction()
-	functionMap["eden"] = function.NewEmptyFunction()
+	//models with contrasts get every function once per contrast
+	for c := range contrastCount() {
+		for _, g := range modelDefinition.Graphs {
+			for _, identifier := range g.Functions {
+				if functionMap[functionKey(g.Id, identifier, c)] == nil {
+					functionMap[functionKey(g.Id, identifier, c)] = function.NewEmptyFunction()
+				}
+			}
+		}
+	}
 }
 
-// creates the graph containers for the different graphs
-// this is the place to add graphs to the GUI
+// creates the graph containers for the graphs of the model definition
 func registerGraphs() *fyne.Container {
+	graphs := make([]fyne.CanvasObject, 0, len(modelDefinition.Graphs))
+	for _, g := range modelDefinition.Graphs {
+		functions := make(function.Functions, 0, len(g.Functions)*contrastCount())
+		for c := range contrastCount() {
+			for _, identifier := range g.Functions {
+				functions = append(functions, functionMap[functionKey(g.Id, identifier, c)])
+			}
+		}
+
+		config := &graph.GraphConfig{
+			//title shown inside the GUI
+			Title: g.Title,
 
-	//a graph needs to be added to the graphMap using a unique identifier so we can further handle it
-	graphMap["intensity"] = graph.NewGraphCanvas(&graph.GraphConfig{
+			//use logarithmic scaling (both x and y axis)
+			IsLog: g.IsLog,
 
-		//title shown inside the GUI
-		Title: "Intensity Graph",
+			Functions: functions,
 
-		//use logarithmic scaling (both x and y axis)
-		IsLog: true,
+			//optional names of the functions, data tracks can be assigned to one of them (e.g. spin channels)
+			Channels: g.Channels,
+
+			//recalculate the intensities on the q values of the remaining data tracks
+			OnDataTracksChanged: trigger.Recalc,
+		}
 
-		//use magic scaling: p.Y = math.Pow(p.X, 4) * p.Y;  p.Error = math.Pow(p.X, 4) * p.Error
-		AdaptDraw: true,
+		//reflectivity graphs can be drawn as R, R·q⁴, R/R_F (fresnel reflectivity of the current substrate) or log R
+		if slices.ContainsFunc(g.Functions, isReflectivityFunction) {
+			config.Transforms = graph.ReflectivityTransforms(fresnelReflectivity)
+			config.Transform = g.Transform
+			if config.Transform == "" && g.AdaptDraw {
+				config.Transform = graph.Q4Transform{}.Name()
+			}
+			// data tracks of overlapping q ranges can be merged into one track
+			config.Actions = append(config.Actions, graph.GraphAction{Name: "Stitch", OnTapped: showStitchDialog})
+			// q ranges of data tracks can be excluded from fits
+			config.Actions = append(config.Actions, graph.GraphAction{Name: "Mask", OnTapped: showMaskDialog})
+		}
 
-		//chose the function to show inside the graph by it's identifier
-		Functions: function.Functions{functionMap["intensity"]},
+		//the born approximation is only calculated while it is switched on in its graph
+		if slices.Contains(g.Functions, "born") {
+			config.Toggles = append(config.Toggles, graph.GraphToggle{Name: "Born", Checked: bornGraphs[g.Id], OnChanged: func(_ *graph.GraphCanvas, checked bool) {
+				bornGraphs[g.Id] = checked
+				trigger.Recalc()
+			}})
+		}
+
+		//data tracks of fitted graphs can be assigned to a contrast
+		if len(modelDefinition.Contrasts) > 1 && slices.ContainsFunc(g.Functions, isFitFunction) {
+			config.Selectors = append(config.Selectors, graph.TrackSelector{Name: contrastSelector, Options: modelDefinition.Contrasts})
+		}
 
 		//optionally set an x-range to plot, points outside it are ignored
-		DisplayRange: &graph.GraphRange{
-			Min: 0.01,
-			Max: math.MaxFloat64,
-		},
-	})
-
-	graphMap["eden"] = graph.NewGraphCanvas(&graph.GraphConfig{
-		Title:     "Edensity Graph",
-		IsLog:     false,
-		AdaptDraw: false,
-		Functions: function.Functions{functionMap["eden"]},
-	})
-
-	//chose how you like to arrange the graphs insige the GUI
-	return container.NewGridWithColumns(2, graphMap["eden"], graphMap["intensity"])
+		if g.DisplayMin != nil || g.DisplayMax != nil {
+			config.DisplayRange = &graph.GraphRange{Min: -math.MaxFloat64, Max: math.MaxFloat64}
+			if g.DisplayMin != nil {
+				config.DisplayRange.Min = *g.DisplayMin
+			}
+			if g.DisplayMax != nil {
+				config.DisplayRange.Max = *g.DisplayMax
+			}
+		}
+
+		//a graph needs to be added to the graphMap using a unique identifier so we can further handle it
+		graphMap[g.Id] = graph.NewGraphCanvas(config)
+		graphs = append(graphs, graphMap[g.Id])
+	}
+
+	columns := modelDefinition.Columns
+	if columns <= 0 {
+		columns = len(graphs)
+	}
+
+	graphGrid = container.NewGridWithColumns(columns, graphs...)
+	updateGraphVisibility()
+	return graphGrid
+}
+
+// shows the graphs of the spin channels only while the layer stack is magnetic, all other graphs are always shown
+func updateGraphVisibility() {
+	for _, g := range modelDefinition.Graphs {
+		canvas := graphMap[g.Id]
+		if canvas == nil {
+			continue
+		}
+		if !layerStack.Magnetic && !slices.ContainsFunc(g.Functions, func(identifier string) bool {
+			return !slices.Contains(polarisedFunctions, identifier)
+		}) {
+			canvas.Hide()
+		} else {
+			canvas.Show()
+		}
+	}
+	if graphGrid != nil {
+		graphGrid.Refresh()
+	}
 }
 
 // creates and registers the parameter and adds them to the parameter repository
-// this is the place to alter parameters:
+// defaults, limits and fit flags are taken from the model definition
 func registerParams() *fyne.Container {
-	//created with a group name, an individual name and a default value
-	//you can get parameters as a group or individually (combining group and individual name) later on
-	//this can be helpful to easily pass similar parameters to a function and iterate over them
-	edenA, _ := param.FloatMinMax("eden", "Eden a", 0.0)
-	eden1, _ := param.FloatMinMax("eden", "Eden 1", 0.346197)
-	eden2, _ := param.FloatMinMax("eden", "Eden 2", 0.458849)
-	edenB, _ := param.FloatMinMax("eden", "Eden b", 0.334000)
-
-	roughnessA1, _ := param.FloatMinMax("rough", "Roughness a/1", 3.39544)
-	roughness12, _ := param.FloatMinMax("rough", "Roughness 1/2", 2.15980)
-	roughness2B, _ := param.FloatMinMax("rough", "Roughness 2/b", 3.90204)
-
-	thickness1, _ := param.FloatMinMax("thick", "Thickness 1", 14.2657)
-	thickness2, _ := param.FloatMinMax("thick", "Thickness 2", 10.6906)
-
-	//parameters can be created with (above) or without (below) two additional fields for minimum and maximum values
-	deltaQ, _ := param.Float("general", "deltaq", -0.000305927)
-	background, _ := param.Float("general", "background", 1.43793e-7)
-	scaling, _ := param.Float("general", "scaling", 0.888730)
-
-	//you can chose how to arrange the parameters inside the GUI here
-	//by now it's 4x4 partitioning where some partitions are left empty
+	//the layer parameters (eden, roughness, thickness, absorption) are generated by the layer stack
+	//layers can be added and removed at runtime, see layers.go
+	layerParams := container.NewVBox()
+	buildLayerParams(layerParams)
+	layerParamsContainer = layerParams
+
+	//general parameters: deltaq, background, scaling, the relative resolution dQ/Q, maxslice and the footprint
+	//data files with a fourth column use their own dQ instead of the relative resolution
+	general := make([]fyne.CanvasObject, 0, len(generalParameters))
+	for _, spec := range generalParameters {
+		canvasObject := createModelParameter(spec, false)
+		if !isLocalParameter(spec.Group, spec.Name) {
+			general = append(general, canvasObject)
+		}
+	}
+
+	//additional parameters of the model definition, they are not used by the built-in functions
+	//but can be fetched with param.GetFloat or param.GetFloats
+	additional := make([]fyne.CanvasObject, 0)
+	for _, spec := range additionalParameterSpecs() {
+		canvasObject := createModelParameter(spec, false)
+		if !isLocalParameter(spec.Group, spec.Name) {
+			additional = append(additional, canvasObject)
+		}
+	}
+
+	//the spline knots (position, eden, absorption) of the free-form profile, see spline.go
+	splineParams := container.NewVBox()
+	buildSplineParams(splineParams)
+	splineParamsContainer = splineParams
+
 	containers := container.NewVBox(
-		container.NewGridWithColumns(4, edenA, eden1, eden2, edenB),
-		container.NewGridWithColumns(4, roughnessA1, roughness12, roughness2B),
-		container.NewGridWithColumns(4, thickness1, thickness2),
-		container.NewGridWithColumns(4, deltaQ, background, scaling),
+		createLayerButtons(layerParams),
+		layerParams,
+		createSplineButtons(splineParams),
+		splineParams,
+		container.NewGridWithColumns(4, general...),
 	)
+	if len(additional) > 0 {
+		containers.Add(container.NewGridWithColumns(4, additional...))
+	}
+
+	//local parameters of the contrasts, all other parameters are shared between the contrasts
+	for _, row := range buildContrastParams() {
+		containers.Add(row)
+	}
 
 	//makes a scrollbar for the parameters
 	con2 := container.NewScroll(containers)
@@ -328,63 +490,55 @@ func registerParams() *fyne.Container {
 	return container.NewStack(con2)
 }
 
-// Insert your adapted physical calculations and parameters here!
 // RecalculateData recalculates the data for the current graphs
-// current parameter values need to be fetched, the physical calculations done and resulting points set to the functions
+// current parameter values are fetched, the physical calculations done and resulting points set to the functions
 func RecalculateData() {
 	// Fetch all parameters here
-	// Get current parameters by group identifier
-	eden, err := param.GetFloats("eden")
-	if err != nil {
-		log.Println("Error while getting eden parameters:", err)
-		return
-	}
-	d, err := param.GetFloats("thick")
-	if err != nil {
-		log.Println("Error while getting thickness parameters:", err)
-		return
-	}
-	sigma, err := param.GetFloats("rough")
-	if err != nil {
-		log.Println("Error while getting roughness parameters:", err)
-		return
-	}
-
-	// get general parameters individually
-	delta, err := param.GetFloat("general", "deltaq")
-	if err != nil {
-		log.Println("Error while getting deltaq parameter:", err)
-		return
-	}
-	background, err := param.GetFloat("general", "background")
+	layout, err := newFitLayout()
 	if err != nil {
-		log.Println("Error while getting background parameter:", err)
+		log.Println("Error while getting parameters:", err)
 		return
 	}
-	scaling, err := param.GetFloat("general", "scaling")
+	values, err := layout.currentValues()
 	if err != nil {
-		log.Println("Error while getting scaling parameter:", err)
+		log.Println("Error while getting parameters:", err)
 		return
 	}
 
-	// calculate all functions which need to be updated here
+	for c := range contrastCount() {
+		contrastValues, err := layout.values(values, c)
+		if err != nil {
+			log.Println("Error while getting parameters:", err)
+			return
+		}
+		state, err := newModelState(contrastValues)
+		if err != nil {
+			log.Println("Error while creating model state:", err)
+			return
+		}
+		if c == 0 {
+			if ambient, substrate, err := state.mediaSLDs(); err == nil {
+				fresnelMedia = [2]complex128{ambient, substrate}
+			}
+		}
 
-	// calculate edensities
-	edenPoints, err := physics.GetEdensities(eden, d, sigma)
-	//only potential error handling
-	if err != nil {
-		log.Println("Error while calculating edensities:", err)
-		return
-	} else {
-		//set points to function which is automatically shown inside the graph
-		functionMap["eden"].SetData(edenPoints)
+		// calculate all functions shown in graphs on the qz axis of the graph
+		for _, g := range modelDefinition.Graphs {
+			graphState := state.withDataSets(graphDataSets(graphMap[g.Id], c))
+			for _, identifier := range g.Functions {
+				if identifier == "born" && !bornGraphs[g.Id] {
+					functionMap[functionKey(g.Id, identifier, c)].SetData(function.Points{})
+					continue
+				}
+				points, err := modelFunctions[identifier](graphState)
+				//only potential error handling
+				if err != nil {
+					log.Printf("Error while calculating %s: %v\n", functionKey(g.Id, identifier, c), err)
+					continue
+				}
+				//set points to function which is automatically shown inside the graph
+				functionMap[functionKey(g.Id, identifier, c)].SetData(points)
+			}
+		}
 	}
-
-	// calculate intensities
-	intensityPoints := physics.CalculateIntensityPoints(edenPoints, delta, &physics.IntensityOptions{
-		Background: background,
-		Scaling:    scaling,
-	})
-	//set points to function which is automatically shown inside the graph
-	functionMap["intensity"].SetData(intensityPoints)
 }
@@ -0,0 +1,82 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// returns the masked ranges and the number of single masked points of a data track
+func maskDescription(track *function.Function) string {
+	if track.Mask.IsEmpty() {
+		return "nothing masked"
+	}
+	ranges := make([]string, len(track.Mask.Ranges))
+	for i, r := range track.Mask.Ranges {
+		ranges[i] = fmt.Sprintf("%g - %g", r.Min, r.Max)
+	}
+	return fmt.Sprintf("ranges: %s\npoints: %d", strings.Join(ranges, ", "), len(track.Mask.Points))
+}
+
+// shows the dialog to exclude q ranges of the data tracks of a graph from fits
+// single points are masked by clicking them in the graph
+func showMaskDialog(canvas *graph.GraphCanvas) {
+	tracks := canvas.GetDataTracks()
+	if len(tracks) == 0 {
+		dialog.ShowError(errors.New("no data tracks to mask"), MainWindow)
+		return
+	}
+	labels := make([]string, len(tracks))
+	for i, track := range tracks {
+		labels[i] = trackLabel(track, i)
+	}
+
+	lblMask := widget.NewLabel("")
+	selTrack := widget.NewSelect(labels, nil)
+	selected := func() *function.Function {
+		return tracks[max(selTrack.SelectedIndex(), 0)]
+	}
+	selTrack.OnChanged = func(string) {
+		lblMask.SetText(maskDescription(selected()))
+	}
+	selTrack.SetSelectedIndex(0)
+
+	entMin := widget.NewEntry()
+	entMin.SetPlaceHolder("q min")
+	entMax := widget.NewEntry()
+	entMax.SetPlaceHolder("q max")
+
+	btnAdd := widget.NewButton("Mask Range", func() {
+		minQ, errMin := strconv.ParseFloat(strings.TrimSpace(entMin.Text), 64)
+		maxQ, errMax := strconv.ParseFloat(strings.TrimSpace(entMax.Text), 64)
+		if errMin != nil || errMax != nil {
+			dialog.ShowError(errors.New("invalid q range"), MainWindow)
+			return
+		}
+		canvas.MaskDataTrackRange(selected(), minQ, maxQ)
+		lblMask.SetText(maskDescription(selected()))
+	})
+	btnClear := widget.NewButton("Clear Mask", func() {
+		canvas.ClearDataTrackMask(selected())
+		lblMask.SetText(maskDescription(selected()))
+	})
+
+	content := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Track", selTrack),
+			widget.NewFormItem("Range", container.NewGridWithColumns(2, entMin, entMax)),
+		),
+		container.NewGridWithColumns(2, btnAdd, btnClear),
+		lblMask,
+		widget.NewLabel("Click a data point in the graph to mask or unmask it."),
+	)
+
+	dialog.ShowCustom("Mask Data", "Close", content, MainWindow)
+}
@@ -0,0 +1,232 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// inputs of the material densities by the name of their parameter, created once and kept when the stack changes
+var materialObjects = make(map[string]fyne.CanvasObject)
+
+// returns the material defining an eden parameter or nil if the parameter is not defined by a formula
+func findMaterial(group, name string) *io.MaterialDefinition {
+	for i, m := range modelDefinition.Materials {
+		if m.Group == group && m.Name == name {
+			return &modelDefinition.Materials[i]
+		}
+	}
+	return nil
+}
+
+// returns the name of the density parameter of a material, "Eden 1" with the formula SiO2 becomes "Density 1 (SiO2)"
+func materialParameterName(m *io.MaterialDefinition) string {
+	return fmt.Sprintf("%s (%s)", strings.Replace(m.Name, "Eden", "Density", 1), m.Formula)
+}
+
+// returns the materials whose eden parameter is part of the current layer stack or spline profile
+func activeMaterials() []*io.MaterialDefinition {
+	specs := slices.Concat(layerStack.Parameters(), splineProfile.Parameters())
+
+	materials := make([]*io.MaterialDefinition, 0)
+	for i, m := range modelDefinition.Materials {
+		if slices.ContainsFunc(specs, func(spec physics.ParameterSpec) bool {
+			return spec.Group == m.Group && spec.Name == m.Name
+		}) {
+			materials = append(materials, &modelDefinition.Materials[i])
+		}
+	}
+	return materials
+}
+
+// returns the specs of the density parameters of the active materials in the order expected by newModelState
+func materialSpecs() []physics.ParameterSpec {
+	materials := activeMaterials()
+
+	specs := make([]physics.ParameterSpec, len(materials))
+	for i, m := range materials {
+		specs[i] = physics.ParameterSpec{Group: physics.DENSITY_GROUP, Name: materialParameterName(m), Default: m.Density}
+	}
+	return specs
+}
+
+// returns the density input of a material, it is shown instead of the eden parameter spec
+// the eden parameter still exists but is not fitted, its value is calculated from the density
+func createMaterialParameter(spec physics.ParameterSpec, m *io.MaterialDefinition) fyne.CanvasObject {
+	if group := param.GetFloatGroup(spec.Group); group != nil && group.GetParam(spec.Name) != nil {
+		group.GetParam(spec.Name).SetCheck(false)
+	}
+
+	name := materialParameterName(m)
+	if materialObjects[name] == nil {
+		definition := &io.ParameterDefinition{
+			Group:   physics.DENSITY_GROUP,
+			Name:    name,
+			Default: m.Density,
+			Min:     m.Min,
+			Max:     m.Max,
+			Fit:     m.Fit,
+		}
+		materialObjects[name] = createParameterFromDefinition(physics.ParameterSpec{Group: physics.DENSITY_GROUP, Name: name, Default: m.Density}, definition, true)
+	}
+	return materialObjects[name]
+}
+
+// returns a copy of the values (ordered like modelParameterSpecs) with the eden values of the active materials
+// replaced by the sld of their formula and density for the selected radiation
+func applyMaterials(values []float64) ([]float64, error) {
+	specs := modelParameterSpecs()
+	materials := activeMaterials()
+	if len(values) != len(specs) {
+		return nil, fmt.Errorf("model expects %d parameters but got %d", len(specs), len(values))
+	}
+
+	start := len(specs) - len(materials)
+	result := slices.Clone(values)
+	for i, m := range materials {
+		target := slices.IndexFunc(specs, func(spec physics.ParameterSpec) bool {
+			return spec.Group == m.Group && spec.Name == m.Name
+		})
+		sld, err := physics.FormulaSLD(m.Formula, values[start+i], radiation)
+		if err != nil {
+			return nil, err
+		}
+		result[target] = sld
+	}
+	return result, nil
+}
+
+// checks the materials of a model definition, their eden parameter has to be in the eden, repeat or spline group
+func validateMaterials(model *io.ModelDefinition) error {
+	for _, m := range model.Materials {
+		if !slices.Contains([]string{physics.EDEN_GROUP, physics.REPEAT_GROUP, physics.SPLINE_GROUP}, m.Group) || !strings.Contains(m.Name, "Eden") {
+			return fmt.Errorf("model definition: material '%s/%s' is not an eden parameter", m.Group, m.Name)
+		}
+		if _, err := physics.ParseFormula(m.Formula); err != nil {
+			return fmt.Errorf("model definition: material '%s/%s': %w", m.Group, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// returns the eden and absorption parameters which can be set by the calculator (not defined by a material and not local)
+func calculatorTargets() []physics.ParameterSpec {
+	targets := make([]physics.ParameterSpec, 0)
+	for _, spec := range modelParameterSpecs() {
+		if (strings.Contains(spec.Name, "Eden") || isAbsorptionParameter(spec)) && findMaterial(spec.Group, spec.Name) == nil && !isLocalParameter(spec.Group, spec.Name) {
+			targets = append(targets, spec)
+		}
+	}
+	return targets
+}
+
+// reports whether the parameter is the absorption of a medium, spline knot or repeat layer
+func isAbsorptionParameter(spec physics.ParameterSpec) bool {
+	return strings.Contains(spec.Name, "Absorption")
+}
+
+// shows the calculator of the x-ray sld (electron density and absorption at Cu Kα) and neutron sld of a chemical
+// formula and a mass density, the value for the selected radiation can be applied to an eden or absorption parameter
+func showMaterialCalculator() {
+	entFormula := widget.NewEntry()
+	entFormula.SetPlaceHolder("e.g. SiO2")
+	entDensity := widget.NewEntry()
+	entDensity.SetPlaceHolder("g/cm³")
+	lblResult := widget.NewLabel("")
+
+	targets := calculatorTargets()
+	names := make([]string, len(targets))
+	for i, spec := range targets {
+		names[i] = spec.Name
+	}
+	selTarget := widget.NewSelect(names, nil)
+
+	density := func() (float64, error) {
+		value, err := strconv.ParseFloat(strings.TrimSpace(entDensity.Text), 64)
+		if err != nil {
+			return 0, errors.New("invalid mass density")
+		}
+		return value, nil
+	}
+
+	update := func(string) {
+		rho, err := density()
+		if err != nil {
+			lblResult.SetText(err.Error())
+			return
+		}
+		eden, err := physics.FormulaXrayEden(entFormula.Text, rho)
+		if err != nil {
+			lblResult.SetText(err.Error())
+			return
+		}
+		absorption, err := physics.FormulaXrayAbsorption(entFormula.Text, rho)
+		if err != nil {
+			lblResult.SetText(err.Error())
+			return
+		}
+		sld, err := physics.FormulaNeutronSLD(entFormula.Text, rho)
+		if err != nil {
+			lblResult.SetText(err.Error())
+			return
+		}
+		neutronAbsorption, err := physics.FormulaNeutronAbsorption(entFormula.Text, rho)
+		if err != nil {
+			lblResult.SetText(err.Error())
+			return
+		}
+		lblResult.SetText(fmt.Sprintf("X-ray eden (Cu Kα): %.6f e/Å³\nX-ray absorption (Cu Kα): %.6f e/Å³\nNeutron SLD: %.4f ·10⁻⁶ Å⁻²\nNeutron absorption: %.4f ·10⁻⁶ Å⁻²",
+			eden, absorption, sld*1e6, neutronAbsorption*1e6))
+	}
+	entFormula.OnChanged = update
+	entDensity.OnChanged = update
+
+	btnApply := widget.NewButton("Apply", func() {
+		if selTarget.SelectedIndex() < 0 {
+			return
+		}
+		rho, err := density()
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		target := targets[selTarget.SelectedIndex()]
+		calculate := physics.FormulaSLD
+		if isAbsorptionParameter(target) {
+			calculate = physics.FormulaAbsorption
+		}
+		value, err := calculate(entFormula.Text, rho, radiation)
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		if err := param.SetFloat(target.Group, target.Name, value); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	})
+
+	// the element table does not cover the whole periodic table
+	lblElements := widget.NewLabel("Supported elements: " + strings.Join(physics.ElementSymbols(), " "))
+	lblElements.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Formula", entFormula),
+			widget.NewFormItem("Density", entDensity),
+		),
+		lblElements,
+		lblResult,
+		container.NewBorder(nil, nil, nil, btnApply, selTarget),
+	)
+	dialog.ShowCustom("SLD Calculator", "Close", content, MainWindow)
+}
@@ -0,0 +1,643 @@
+package gui
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"slices"
+
+	"fyne.io/fyne/v2"
+)
+
+var (
+	//go:embed default_model.yaml
+	defaultModelDefinition []byte
+
+	// model definition the parameters, functions and graphs are created from
+	modelDefinition = mustDecodeModelDefinition(defaultModelDefinition, ".yaml")
+
+	// general parameters used by the built-in physics functions, the model definition can override the defaults
+	generalParameters = []physics.ParameterSpec{
+		{Group: "general", Name: "deltaq", Default: 0.0},
+		{Group: "general", Name: "background", Default: 0.0},
+		{Group: "general", Name: "scaling", Default: 1.0},
+		{Group: "general", Name: "resolution", Default: 0.0},
+		{Group: "general", Name: "maxslice", Default: 2.0},
+		{Group: "general", Name: "beamwidth", Default: 0.1},
+		{Group: "general", Name: "samplelength", Default: 10.0},
+		{Group: "general", Name: "wavelength", Default: 1.5406},
+	}
+
+	// physics functions which can be shown in graphs, referenced by their identifier in the model definition
+	modelFunctions = map[string]modelFunction{
+		"eden": func(m *modelState) (function.Points, error) {
+			return m.edenProfile()
+		},
+		"absorption": func(m *modelState) (function.Points, error) {
+			return m.absorptionProfile()
+		},
+		"magnetic": func(m *modelState) (function.Points, error) {
+			parallel, perpendicular, err := m.magneticProfiles()
+			if err != nil {
+				return nil, err
+			}
+
+			// magnitude of the magnetic sld
+			points := make(function.Points, len(parallel))
+			for i := range parallel {
+				points[i] = &function.Point{X: parallel[i].X, Y: math.Hypot(parallel[i].Y, perpendicular[i].Y)}
+			}
+			return points, nil
+		},
+		"intensity": func(m *modelState) (function.Points, error) {
+			return m.intensity(m.engine)
+		},
+
+		// kinematic intensity (born approximation) of the same profile, only calculated for graphs showing it (see bornGraphs)
+		"born": func(m *modelState) (function.Points, error) {
+			return m.intensity(physics.KinematicEngine{})
+		},
+
+		// spin channels of polarised neutron reflectivity
+		"intensity++": polarisedFunction(physics.UP_UP),
+		"intensity--": polarisedFunction(physics.DOWN_DOWN),
+		"intensity+-": polarisedFunction(physics.UP_DOWN),
+		"intensity-+": polarisedFunction(physics.DOWN_UP),
+	}
+
+	// functions which can be fitted to data tracks
+	fitFunctions = []string{"intensity", "intensity++", "intensity--", "intensity+-", "intensity-+"}
+
+	// spin channel functions, graphs only showing them are hidden for non magnetic stacks
+	polarisedFunctions = []string{"intensity++", "intensity--", "intensity+-", "intensity-+"}
+)
+
+// describes how the layer stack is turned into media for the reflectivity calculation
+type ProfileMode string
+
+const (
+	// erf profile sliced into ZNUMBER media
+	ProfileMicroslices = ProfileMode("Microslices")
+	// erf profile sliced on a z axis refined near the interfaces, slices are at most maxslice thick
+	ProfileAdaptive = ProfileMode("Adaptive")
+	// layers with sharp interfaces damped by roughness factors
+	ProfileSlabs = ProfileMode("Slabs")
+	// free-form profile interpolating the spline knots instead of the layer stack, sliced at most maxslice thick
+	ProfileSpline = ProfileMode("Spline")
+)
+
+// calculates the points of a function shown in a graph based on the current model state
+type modelFunction func(m *modelState) (function.Points, error)
+
+// modelState holds the parameter values of one evaluation of the model
+// profiles are calculated once and shared between the functions
+type modelState struct {
+	eden, d, sigma, absorption []float64
+
+	// magnetic sld and angle {1,...,n,b}, nil for non magnetic stacks
+	magnetic, angle []float64
+
+	deltaq, background, scaling, resolution, maxSlice float64
+
+	// illumination of the sample, nil if the footprint correction is off
+	footprint *physics.Footprint
+
+	// experimental data points (needed for per point resolution), see withDataSets
+	dataPoints function.Points
+
+	// q values the intensities are calculated for, every graph has its own (see withQZAxis)
+	qzAxis []float64
+
+	// knots of the spline profile
+	spline *physics.SplineValues
+
+	// selected reflectivity engine, profile mode, roughness model (slabs only) and spline interpolation (spline only)
+	engine              physics.ReflectivityEngine
+	profile             ProfileMode
+	roughnessModel      physics.RoughnessModel
+	splineInterpolation physics.SplineInterpolation
+
+	edenPoints       function.Points
+	absorptionPoints function.Points
+	polarisedPoints  []function.Points
+}
+
+// creates a model state from the parameter values ordered like modelParameterSpecs
+func newModelState(values []float64) (*modelState, error) {
+	stackCount := layerStack.ParameterCount()
+	splineStart := stackCount + len(generalParameters)
+	splineEnd := splineStart + splineProfile.ParameterCount()
+
+	// eden values of parameters defined by a formula are calculated from the density
+	values, err := applyMaterials(values)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := layerStack.Split(values[:stackCount])
+	if err != nil {
+		return nil, err
+	}
+	// superlattices are expanded into single layers
+	layers := split.Expand(repetitions())
+
+	spline, err := splineProfile.Split(values[splineStart:splineEnd])
+	if err != nil {
+		return nil, err
+	}
+
+	return &modelState{
+		eden:                layers.Eden,
+		d:                   layers.Thickness,
+		sigma:               layers.Roughness,
+		absorption:          layers.Absorption,
+		magnetic:            layers.Magnetic,
+		angle:               layers.Angle,
+		deltaq:              values[stackCount],
+		background:          values[stackCount+1],
+		scaling:             values[stackCount+2],
+		resolution:          values[stackCount+3],
+		maxSlice:            values[stackCount+4],
+		footprint:           newFootprint(values[stackCount+5], values[stackCount+6], values[stackCount+7]),
+		qzAxis:              physics.GetDefaultQZAxis(physics.DEFAULT_QZ_NUMBER),
+		spline:              spline,
+		engine:              reflectivityEngine,
+		profile:             profileMode,
+		roughnessModel:      roughnessModel,
+		splineInterpolation: splineInterpolation,
+	}, nil
+}
+
+// returns a copy of the state calculating the intensities on another qz axis
+// the profiles are calculated once and shared with the copy
+func (m *modelState) withQZAxis(qzaxis []float64) *modelState {
+	// errors are returned again by the functions of the copy
+	_, _ = m.edenProfile()
+	_, _ = m.absorptionProfile()
+
+	state := *m
+	state.qzAxis = qzaxis
+	state.dataPoints = nil
+	state.polarisedPoints = nil
+	return &state
+}
+
+// returns a copy of the state calculating the intensities at the q values of the data sets
+// with the resolution of their points, the default qz axis without data points.
+// Data sets may share q values with a different resolution, the fit therefore calculates every data track on its own
+func (m *modelState) withDataSets(dataSets []function.Points) *modelState {
+	state := m.withQZAxis(physics.GetDataQZAxis(dataSets))
+	state.dataPoints = slices.Concat(dataSets...)
+	return state
+}
+
+// returns the points of the data tracks of a graph assigned to a contrast
+// every graph has its own qz axis, so loading or removing data only changes the intensities of its graph
+func graphDataSets(canvas *graph.GraphCanvas, contrast int) []function.Points {
+	dataSets := make([]function.Points, 0)
+	for _, track := range canvas.GetDataTracks() {
+		if canvas.GetDataTrackSelection(track, contrastSelector) == contrast {
+			dataSets = append(dataSets, track.GetData())
+		}
+	}
+	return dataSets
+}
+
+// returns the eden profile of the layer stack
+func (m *modelState) edenProfile() (function.Points, error) {
+	if m.edenPoints == nil {
+		var edenPoints function.Points
+		var err error
+		if m.profile == ProfileSpline {
+			edenPoints, err = m.splineProfileOf(m.spline.Eden)
+		} else if m.profile == ProfileAdaptive {
+			edenPoints, err = physics.GetAdaptiveEdensities(m.eden, m.d, m.sigma, m.maxSlice)
+		} else {
+			edenPoints, err = physics.GetEdensities(m.eden, m.d, m.sigma)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.edenPoints = edenPoints
+	}
+
+	return m.edenPoints, nil
+}
+
+// returns the absorption profile of the layer stack
+func (m *modelState) absorptionProfile() (function.Points, error) {
+	if m.absorptionPoints == nil {
+		var absorptionPoints function.Points
+		var err error
+		if m.profile == ProfileSpline {
+			absorptionPoints, err = m.splineProfileOf(m.spline.Absorption)
+		} else if m.profile == ProfileAdaptive {
+			absorptionPoints, err = physics.GetAdaptiveAbsorptions(m.absorption, m.d, m.sigma, m.maxSlice)
+		} else {
+			absorptionPoints, err = physics.GetAbsorptions(m.absorption, m.d, m.sigma)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.absorptionPoints = absorptionPoints
+	}
+
+	return m.absorptionPoints, nil
+}
+
+// returns the spline profile of the knot values
+func (m *modelState) splineProfileOf(values []float64) (function.Points, error) {
+	zAxis, err := physics.GetSplineZAxis(m.spline.Z, m.maxSlice)
+	if err != nil {
+		return nil, err
+	}
+	return physics.GetSplineProfile(zAxis, m.spline.Z, values, m.splineInterpolation)
+}
+
+// returns the slds of the ambient medium and the substrate (the ends of the profile)
+func (m *modelState) mediaSLDs() (ambient, substrate complex128, err error) {
+	edenPoints, err := m.edenProfile()
+	if err != nil {
+		return 0, 0, err
+	}
+	absorptionPoints, err := m.absorptionProfile()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sld, err := physics.GetSLDs(edenPoints, absorptionPoints)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(sld) == 0 {
+		return 0, 0, fmt.Errorf("empty profile")
+	}
+	return sld[0], sld[len(sld)-1], nil
+}
+
+// returns the magnetic profile split into the components parallel and perpendicular to the polarisation axis
+// non magnetic stacks and the spline profile have no magnetisation
+func (m *modelState) magneticProfiles() (parallel, perpendicular function.Points, err error) {
+	edenPoints, err := m.edenProfile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	magnetic, angle := m.magnetic, m.angle
+	if magnetic == nil || m.profile == ProfileSpline {
+		magnetic = make([]float64, len(m.d)+1)
+		angle = make([]float64, len(m.d)+1)
+	}
+
+	zAxis := make([]float64, len(edenPoints))
+	for i, p := range edenPoints {
+		zAxis[i] = p.X
+	}
+
+	return physics.GetMagneticProfiles(zAxis, magnetic, angle, m.d, m.sigma)
+}
+
+// slds of the ambient medium and the substrate of the last recalculation (first contrast), used by the R/R_F transform
+var fresnelMedia = [2]complex128{0, complex(physics.DEFAULT_EDEN*physics.ELECTRON_RADIUS, 0)}
+
+// returns the fresnel reflectivity of the substrate of the last recalculation at the q values
+func fresnelReflectivity(qzaxis []float64) []float64 {
+	return physics.CalculateFresnelReflectivity(qzaxis, fresnelMedia[0], fresnelMedia[1])
+}
+
+// returns the intensity of the profile (microsliced, spline or slab model) calculated with the engine
+func (m *modelState) intensity(engine physics.ReflectivityEngine) (function.Points, error) {
+	opts := m.intensityOptions()
+	opts.Engine = engine
+
+	// slab model with roughness factors instead of the microsliced profile
+	if m.profile == ProfileSlabs {
+		stack, err := physics.NewSlabStack(m.eden, m.d, m.sigma, m.absorption, m.roughnessModel)
+		if err != nil {
+			return nil, err
+		}
+		return physics.CalculateStackIntensityPoints(stack, m.qzAxis, m.deltaq, opts), nil
+	}
+
+	edenPoints, err := m.edenProfile()
+	if err != nil {
+		return nil, err
+	}
+	absorptionPoints, err := m.absorptionProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Absorption = absorptionPoints
+	return physics.CalculateIntensityPoints(edenPoints, m.qzAxis, m.deltaq, opts)
+}
+
+// returns the intensity options of the current state without absorption profile
+func (m *modelState) intensityOptions() *physics.IntensityOptions {
+	return &physics.IntensityOptions{
+		Background: m.background,
+		Scaling:    m.scaling,
+		Resolution: &physics.Resolution{
+			Relative: m.resolution,
+			Points:   m.dataPoints,
+		},
+		Engine:    m.engine,
+		Footprint: m.footprint,
+	}
+}
+
+// creates the footprint of the selected beam profile, nil if the footprint correction is off
+func newFootprint(beamWidth, sampleLength, wavelength float64) *physics.Footprint {
+	if beamProfile == footprintOff {
+		return nil
+	}
+	return &physics.Footprint{
+		BeamWidth:    beamWidth,
+		SampleLength: sampleLength,
+		Wavelength:   wavelength,
+		Profile:      physics.BeamProfile(beamProfile),
+	}
+}
+
+// returns the intensity of all spin channels
+// the spinor calculation needs a microsliced profile, the slab mode uses the fixed z axis
+func (m *modelState) polarisedIntensity() ([]function.Points, error) {
+	if m.polarisedPoints == nil {
+		edenPoints, err := m.edenProfile()
+		if err != nil {
+			return nil, err
+		}
+		absorptionPoints, err := m.absorptionProfile()
+		if err != nil {
+			return nil, err
+		}
+		parallel, perpendicular, err := m.magneticProfiles()
+		if err != nil {
+			return nil, err
+		}
+
+		opts := m.intensityOptions()
+		opts.Absorption = absorptionPoints
+		polarisedPoints, err := physics.CalculatePolarisedIntensityPoints(edenPoints, parallel, perpendicular, m.qzAxis, m.deltaq, opts)
+		if err != nil {
+			return nil, err
+		}
+		m.polarisedPoints = polarisedPoints
+	}
+
+	return m.polarisedPoints, nil
+}
+
+// creates a model function returning the intensity of a spin channel, only calculated for magnetic stacks
+func polarisedFunction(channel int) modelFunction {
+	return func(m *modelState) (function.Points, error) {
+		if m.magnetic == nil {
+			return function.Points{}, nil
+		}
+		channels, err := m.polarisedIntensity()
+		if err != nil {
+			return nil, err
+		}
+		return channels[channel], nil
+	}
+}
+
+// returns the specs of all parameters used by the model in the order expected by newModelState
+// layer stack, general parameters, spline knots and densities of the materials
+func modelParameterSpecs() []physics.ParameterSpec {
+	return slices.Concat(layerStack.Parameters(), generalParameters, splineProfile.Parameters(), materialSpecs())
+}
+
+// returns all parameters used by the model in the order expected by newModelState
+func modelParameters() []*param.Parameter[float64] {
+	specs := modelParameterSpecs()
+
+	parameters := make([]*param.Parameter[float64], len(specs))
+	for i, spec := range specs {
+		if group := param.GetFloatGroup(spec.Group); group != nil {
+			parameters[i] = group.GetParam(spec.Name)
+		}
+	}
+
+	return parameters
+}
+
+// LoadModelDefinition loads a model definition file (.json or .yaml), needs to be called before Start
+func LoadModelDefinition(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	model, err := io.DecodeModelDefinition(data, filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+
+	// check if all functions and transforms exist
+	for _, g := range model.Graphs {
+		for _, f := range g.Functions {
+			if _, ok := modelFunctions[f]; !ok {
+				return fmt.Errorf("model definition: graph '%s' uses unknown function '%s'", g.Id, f)
+			}
+		}
+		if g.Transform != "" && !slices.ContainsFunc(graph.ReflectivityTransforms(nil), func(t graph.DisplayTransform) bool {
+			return t.Name() == g.Transform
+		}) {
+			return fmt.Errorf("model definition: graph '%s' uses unknown transform '%s'", g.Id, g.Transform)
+		}
+	}
+
+	// local parameters need to be a layer, general or defined parameter
+	for _, local := range model.LocalParameters {
+		if !slices.Contains(layerGroupOrder, local.Group) && local.Group != physics.SPLINE_GROUP && !slices.ContainsFunc(model.Parameters, func(p io.ParameterDefinition) bool {
+			return p.Group == local.Group && p.Name == local.Name
+		}) && !slices.ContainsFunc(generalParameters, func(spec physics.ParameterSpec) bool {
+			return spec.Group == local.Group && spec.Name == local.Name
+		}) {
+			return fmt.Errorf("model definition: unknown local parameter '%s/%s'", local.Group, local.Name)
+		}
+	}
+
+	if err := validateMaterials(model); err != nil {
+		return err
+	}
+
+	modelDefinition = model
+	layerStack = newModelLayerStack(model)
+	splineProfile = physics.NewSplineProfile(model.SplineKnots)
+
+	return nil
+}
+
+// creates the layer stack of a model definition
+func newModelLayerStack(model *io.ModelDefinition) *physics.LayerStack {
+	stack := physics.NewLayerStack(model.Layers)
+	if model.Magnetic {
+		stack = physics.NewMagneticLayerStack(model.Layers)
+	}
+	stack.RepeatLayers = model.RepeatLayers
+	return stack
+}
+
+func mustDecodeModelDefinition(data []byte, extension string) *io.ModelDefinition {
+	model, err := io.DecodeModelDefinition(data, extension)
+	if err != nil {
+		panic(fmt.Errorf("built-in model definition is invalid: %w", err))
+	}
+	return model
+}
+
+// returns the definition of a parameter in the model definition or nil if it is not defined
+func findParameterDefinition(group, name string) *io.ParameterDefinition {
+	for i, p := range modelDefinition.Parameters {
+		if p.Group == group && p.Name == name {
+			return &modelDefinition.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// creates a float parameter with the default, limits and fit flag of the model definition
+// limited parameters always get min and max fields
+func createModelParameter(spec physics.ParameterSpec, limited bool) fyne.CanvasObject {
+	return createParameterFromDefinition(spec, findParameterDefinition(spec.Group, spec.Name), limited)
+}
+
+// creates a float parameter with the default, limits and fit flag of a definition (may be nil)
+func createParameterFromDefinition(spec physics.ParameterSpec, definition *io.ParameterDefinition, limited bool) fyne.CanvasObject {
+	value := spec.Default
+	if definition != nil {
+		value = definition.Default
+		limited = limited || definition.Min != nil || definition.Max != nil
+	}
+
+	var canvasObject fyne.CanvasObject
+	var p *param.Parameter[float64]
+	if limited {
+		canvasObject, p = param.FloatMinMax(spec.Group, spec.Name, value)
+	} else {
+		canvasObject, p = param.Float(spec.Group, spec.Name, value)
+	}
+
+	if definition != nil {
+		if definition.Min != nil {
+			_ = p.GetRelative("min").Set(*definition.Min)
+		}
+		if definition.Max != nil {
+			_ = p.GetRelative("max").Set(*definition.Max)
+		}
+		p.SetCheck(definition.Fit)
+	}
+
+	return canvasObject
+}
+
+// returns the parameters of the model definition which are neither layer, general nor spline parameters
+func additionalParameterSpecs() []physics.ParameterSpec {
+	known := modelParameterSpecs()
+
+	specs := make([]physics.ParameterSpec, 0)
+	for _, p := range modelDefinition.Parameters {
+		if slices.ContainsFunc(known, func(spec physics.ParameterSpec) bool {
+			return spec.Group == p.Group && spec.Name == p.Name
+		}) || slices.Contains(layerGroupOrder, p.Group) || p.Group == physics.SPLINE_GROUP {
+			continue
+		}
+		specs = append(specs, physics.ParameterSpec{Group: p.Group, Name: p.Name, Default: p.Default})
+	}
+
+	return specs
+}
+
+// returns the graphs which show a fit function, their data tracks are used for fitting
+func fitGraphs() []*graph.GraphCanvas {
+	graphs := make([]*graph.GraphCanvas, 0)
+	for _, g := range modelDefinition.Graphs {
+		if slices.ContainsFunc(g.Functions, isFitFunction) && graphMap[g.Id] != nil {
+			graphs = append(graphs, graphMap[g.Id])
+		}
+	}
+	return graphs
+}
+
+func isFitFunction(identifier string) bool {
+	return slices.Contains(fitFunctions, identifier)
+}
+
+// reports whether a function is a reflectivity (fit functions and the born approximation)
+func isReflectivityFunction(identifier string) bool {
+	return isFitFunction(identifier) || identifier == "born"
+}
+
+// returns the data tracks of all graphs used for fitting
+func fitDataTracks() function.Functions {
+	tracks := make(function.Functions, 0)
+	for _, g := range fitGraphs() {
+		tracks = append(tracks, g.GetDataTracks()...)
+	}
+	return tracks
+}
+
+// function of a contrast data tracks of a graph are compared to
+type fitTarget struct {
+	graph      string
+	identifier string
+	contrast   int
+}
+
+// data tracks compared to the same function of a contrast
+type fitTargetTracks struct {
+	target fitTarget
+	tracks function.Functions
+}
+
+// returns the data tracks of all graphs used for fitting by the function and contrast they are compared to
+// data tracks of graphs with channels are compared to the function of their channel,
+// all others to the first fit function of their graph.
+// The targets are ordered by graph, contrast and function, so the summed cost does not depend on map order
+func fitTargets() []fitTargetTracks {
+	targets := make([]fitTargetTracks, 0)
+	for _, g := range modelDefinition.Graphs {
+		canvas := graphMap[g.Id]
+		first := slices.IndexFunc(g.Functions, isFitFunction)
+		// data tracks of hidden graphs (e.g. spin channels of a non magnetic stack) are not fitted
+		if canvas == nil || !canvas.Visible() || first == -1 {
+			continue
+		}
+
+		graphTargets := make([]fitTargetTracks, 0)
+		for _, track := range canvas.GetDataTracks() {
+			identifier := g.Functions[first]
+			if len(g.Channels) > 0 {
+				identifier = g.Functions[canvas.GetDataTrackChannel(track)]
+			}
+			if !isFitFunction(identifier) {
+				continue
+			}
+
+			target := fitTarget{graph: g.Id, identifier: identifier, contrast: canvas.GetDataTrackSelection(track, contrastSelector)}
+			if i := slices.IndexFunc(graphTargets, func(t fitTargetTracks) bool { return t.target == target }); i != -1 {
+				graphTargets[i].tracks = append(graphTargets[i].tracks, track)
+			} else {
+				graphTargets = append(graphTargets, fitTargetTracks{target: target, tracks: function.Functions{track}})
+			}
+		}
+
+		slices.SortStableFunc(graphTargets, func(a, b fitTargetTracks) int {
+			if a.target.contrast != b.target.contrast {
+				return a.target.contrast - b.target.contrast
+			}
+			return slices.Index(g.Functions, a.target.identifier) - slices.Index(g.Functions, b.target.identifier)
+		})
+		targets = append(targets, graphTargets...)
+	}
+	return targets
+}
@@ -28,6 +28,10 @@ func IntParameter(defaultValue int) *Parameter[int] {
 	return New(&Config[int]{
 		InitialValue: defaultValue,
 		Validator: func(s string) error {
+			if _, err := strconv.Atoi(s); err != nil {
+				return errors.New("keine gültige Zahl")
+			}
+
 			return nil
 		},
 		Format: StdIntFormater,
@@ -42,7 +46,7 @@ func Int(group, label string, defaultValue int) (fyne.CanvasObject, *Parameter[i
 		iParams[group] = NewGroupElements[int]()
 	}
 
-	if !iParams[group].Check(label) {
+	if iParams[group].Check(label) {
 		log.Fatal(errors.New("parameter key '" + label + "' already exists in group '" + group + "'"))
 	}
 
@@ -0,0 +1,51 @@
+package param
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestIntRegistersNewLabels(t *testing.T) {
+	test.NewTempApp(t)
+
+	Int("test_int", "first", 1)
+	Int("test_int", "second", 2)
+
+	for label, want := range map[string]int{"first": 1, "second": 2} {
+		if !iParams["test_int"].Check(label) {
+			t.Errorf("label %q not registered", label)
+		}
+
+		got, err := GetInt("test_int", label)
+		if err != nil || got != want {
+			t.Errorf("GetInt(%q) = %d, %v; want %d", label, got, err, want)
+		}
+	}
+
+	if iParams["test_int"].Check("third") {
+		t.Errorf("unregistered label reported as existing")
+	}
+}
+
+func TestIntValidator(t *testing.T) {
+	test.NewTempApp(t)
+
+	p := IntParameter(0)
+
+	for _, c := range []struct {
+		input string
+		valid bool
+	}{
+		{"3", true},
+		{"-12", true},
+		{"", false},
+		{"abc", false},
+		{"1.5", false},
+	} {
+		err := p.config.Validator(c.input)
+		if (err == nil) != c.valid {
+			t.Errorf("Validator(%q) = %v, want valid %v", c.input, err, c.valid)
+		}
+	}
+}
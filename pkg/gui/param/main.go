@@ -53,3 +53,8 @@ func GetFloatKeys() []string {
 func GetIntKeys() []string {
 	return slices.Collect(maps.Keys(iParams))
 }
+
+// removes a float parameter group including all of its parameters
+func RemoveFloatGroup(group string) {
+	delete(fParams, group)
+}
@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"fmt"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/trigger"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+var (
+	// settings by their key, they are stored in config files
+	settingsMap = make(map[string]*setting)
+
+	// reflectivity engine used for all intensity calculations
+	reflectivityEngine = physics.DefaultEngine()
+
+	// media used for the intensity calculation (microsliced profile or slab model)
+	profileMode = ProfileMicroslices
+
+	// roughness factors of the slab model
+	roughnessModel = physics.NEVOT_CROCE
+
+	// interpolation between the knots of the spline profile
+	splineInterpolation = physics.NATURAL_SPLINE
+
+	// radiation the slds of materials (eden parameters defined by a formula) are calculated for
+	radiation = physics.XRAY_RADIATION
+
+	// beam profile of the footprint correction or footprintOff
+	beamProfile = footprintOff
+
+	// meaning of the first column of imported data files, converted to qz in 1/Å
+	importColumns = data.QZ_ANGSTROM
+
+	// column delimiter of imported data files
+	importDelimiter = data.AUTO_DELIMITER
+
+	// figure of merit minimised by the fit
+	costFunction = physics.DefaultCostFunction()
+)
+
+// option of the footprint setting without correction
+const footprintOff = "Off"
+
+// setting is an option of the calculation with a fixed set of values, shown as a select in the gui
+type setting struct {
+	options []string
+	widget  *widget.Select
+}
+
+// creates a setting and adds it to the settingsMap
+// onChange is called with the selected value when the selection changes (not for the initial value)
+func newSetting(key string, options []string, selected string, onChange func(value string)) *widget.Select {
+	s := &setting{
+		options: options,
+		widget:  widget.NewSelect(options, nil),
+	}
+	s.widget.SetSelected(selected)
+	s.widget.OnChanged = onChange
+
+	settingsMap[key] = s
+	return s.widget
+}
+
+// returns the selected value of a setting
+func getSetting(key string) (string, error) {
+	s, ok := settingsMap[key]
+	if !ok {
+		return "", fmt.Errorf("no such setting '%s'", key)
+	}
+	return s.widget.Selected, nil
+}
+
+// selects a value of a setting
+func setSetting(key, value string) error {
+	s, ok := settingsMap[key]
+	if !ok {
+		return fmt.Errorf("no such setting '%s'", key)
+	}
+	if !slices.Contains(s.options, value) {
+		return fmt.Errorf("setting '%s' has no option '%s'", key, value)
+	}
+	s.widget.SetSelected(value)
+	return nil
+}
+
+// creates the settings of the calculation
+// this is the place to add settings
+func registerSettings() *fyne.Container {
+	// algorithm used to calculate the reflectivity
+	engine := newSetting("engine", physics.EngineNames(), reflectivityEngine.Name(), func(value string) {
+		selected, err := physics.GetEngine(value)
+		if err != nil {
+			fmt.Println("Error while selecting reflectivity engine:", err)
+			return
+		}
+		reflectivityEngine = selected
+		trigger.Recalc()
+	})
+
+	// microsliced erf profile (fixed or adaptive z axis), slab model with roughness factors (faster for fits)
+	// or free-form spline profile
+	profile := newSetting("profile", []string{string(ProfileMicroslices), string(ProfileAdaptive), string(ProfileSlabs), string(ProfileSpline)}, string(profileMode), func(value string) {
+		profileMode = ProfileMode(value)
+		trigger.Recalc()
+	})
+
+	// roughness factors of the slab model
+	roughness := newSetting("roughness", physics.RoughnessModelNames(), string(roughnessModel), func(value string) {
+		roughnessModel = physics.RoughnessModel(value)
+		trigger.Recalc()
+	})
+
+	// interpolation of the spline profile
+	spline := newSetting("spline", physics.SplineInterpolationNames(), string(splineInterpolation), func(value string) {
+		splineInterpolation = physics.SplineInterpolation(value)
+		trigger.Recalc()
+	})
+
+	// x-ray electron density or neutron sld of the materials
+	radiationSetting := newSetting("radiation", physics.RadiationNames(), string(radiation), func(value string) {
+		radiation = physics.Radiation(value)
+		trigger.Recalc()
+	})
+
+	// footprint correction with the beam profile, uses the general parameters beamwidth, samplelength and wavelength
+	footprint := newSetting("footprint", append([]string{footprintOff}, physics.BeamProfileNames()...), beamProfile, func(value string) {
+		beamProfile = value
+		trigger.Recalc()
+	})
+
+	// unit of the first column of imported files, only used for the next import so no recalculation is needed
+	columns := newSetting("columns", data.ColumnUnitNames(), string(importColumns), func(value string) {
+		importColumns = data.ColumnUnit(value)
+	})
+
+	// delimiter of the columns of imported files
+	delimiter := newSetting("delimiter", data.DelimiterNames(), string(importDelimiter), func(value string) {
+		importDelimiter = data.Delimiter(value)
+	})
+
+	return container.NewHBox(
+		widget.NewLabel("Engine"), engine,
+		widget.NewLabel("Profile"), profile,
+		widget.NewLabel("Roughness"), roughness,
+		widget.NewLabel("Spline"), spline,
+		widget.NewLabel("Radiation"), radiationSetting,
+		widget.NewLabel("Footprint"), footprint,
+		widget.NewLabel("Import"), columns,
+		widget.NewLabel("Delimiter"), delimiter,
+	)
+}
+
+// creates the setting of the cost function, it is shown in the minimizer control panel
+// the cost is only used by the fit, so no recalculation is needed
+func newCostSetting() *widget.Select {
+	return newSetting("cost", physics.CostFunctionNames(), costFunction.Name(), func(value string) {
+		selected, err := physics.GetCostFunction(value)
+		if err != nil {
+			fmt.Println("Error while selecting cost function:", err)
+			return
+		}
+		costFunction = selected
+	})
+}
+
+func createSettingInformation() ([]io.SettingInformation, error) {
+	settings := make([]io.SettingInformation, 0, len(settingsMap))
+	for key := range settingsMap {
+		value, err := getSetting(key)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, io.SettingInformation{Key: key, Value: value})
+	}
+	slices.SortFunc(settings, func(a, b io.SettingInformation) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return settings, nil
+}
+
+func loadSettingInformation(settings []io.SettingInformation) error {
+	for _, s := range settings {
+		if _, ok := settingsMap[s.Key]; !ok {
+			fmt.Printf("Could not load setting %s no such setting in program -> Skipped", s.Key)
+			continue
+		}
+		if err := setSetting(s.Key, s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
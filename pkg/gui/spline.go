@@ -0,0 +1,86 @@
+package gui
+
+import (
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/trigger"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// creates the buttons for adding and removing knots of the spline profile
+func createSplineButtons(splineParams *fyne.Container) *fyne.Container {
+	btnAdd := widget.NewButtonWithIcon("Add Knot", theme.ContentAddIcon(), func() {
+		setSplineProfile(splineParams, physics.NewSplineProfile(splineProfile.Knots+1))
+	})
+	btnRemove := widget.NewButtonWithIcon("Remove Knot", theme.ContentRemoveIcon(), func() {
+		// the spline interpolation needs at least two knots
+		if splineProfile.Knots > physics.MIN_SPLINE_KNOTS {
+			setSplineProfile(splineParams, physics.NewSplineProfile(splineProfile.Knots-1))
+		}
+	})
+
+	return container.NewHBox(btnAdd, btnRemove)
+}
+
+// creates the parameters of the spline profile and adds them to the container, one row per knot
+func buildSplineParams(splineParams *fyne.Container) {
+	specs := splineProfile.Parameters()
+	knots := splineProfile.Knots
+
+	rows := make([]fyne.CanvasObject, 0, knots)
+	for i := range knots {
+		// position, eden and absorption of the knot
+		objects := make([]fyne.CanvasObject, 0, 3)
+		for _, spec := range []physics.ParameterSpec{specs[i], specs[knots+i], specs[2*knots+i]} {
+			canvasObject := createModelParameter(spec, true)
+			if m := findMaterial(spec.Group, spec.Name); m != nil {
+				canvasObject = createMaterialParameter(spec, m)
+			}
+			if !isLocalParameter(spec.Group, spec.Name) {
+				objects = append(objects, canvasObject)
+			}
+		}
+		rows = append(rows, container.NewGridWithColumns(3, objects...))
+	}
+
+	splineParams.Objects = rows
+	splineParams.Refresh()
+}
+
+// replaces the spline profile and rebuilds its parameters
+// values, limits and fit flags of knots which still exist are kept
+func setSplineProfile(splineParams *fyne.Container, newProfile *physics.SplineProfile) {
+	snapshot, err := createParameterInformation()
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, spec := range newProfile.Parameters() {
+		names[spec.Name] = true
+	}
+
+	kept := make([]io.ParameterInformation, 0, len(snapshot))
+	for _, info := range snapshot {
+		if info.Group == physics.SPLINE_GROUP && names[info.Name] {
+			kept = append(kept, info)
+		}
+	}
+
+	param.RemoveFloatGroup(physics.SPLINE_GROUP)
+	splineProfile = newProfile
+	buildSplineParams(splineParams)
+
+	if err := loadParameterInformation(kept); err != nil {
+		dialog.ShowError(err, MainWindow)
+	}
+
+	trigger.Recalc()
+}
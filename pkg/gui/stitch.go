@@ -0,0 +1,101 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/trigger"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// returns the legend entry of a data track or its position if it has no metadata
+func trackLabel(track *function.Function, i int) string {
+	if label := track.Metadata.Label(); label != "" {
+		return fmt.Sprintf("%d: %s", i+1, label)
+	}
+	return fmt.Sprintf("%d: data", i+1)
+}
+
+// shows the dialog to merge data tracks of overlapping q ranges of a reflectivity graph into one track
+func showStitchDialog(canvas *graph.GraphCanvas) {
+	labels := make([]string, 0)
+	for i, track := range canvas.GetDataTracks() {
+		labels = append(labels, trackLabel(track, i))
+	}
+	if len(labels) < 2 {
+		dialog.ShowError(errors.New("at least 2 data tracks needed for stitching"), MainWindow)
+		return
+	}
+
+	checkTracks := widget.NewCheckGroup(labels, nil)
+	checkTracks.SetSelected(labels)
+
+	content := container.NewVBox(
+		widget.NewLabel("Tracks of overlapping q ranges"),
+		checkTracks,
+	)
+
+	dialog.ShowCustomConfirm("Stitch Data Tracks", "Stitch", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		tracks := make(function.Functions, 0)
+		for i, track := range canvas.GetDataTracks() {
+			if slices.Contains(checkTracks.Selected, trackLabel(track, i)) {
+				tracks = append(tracks, track)
+			}
+		}
+		if err := stitchDataTracks(canvas, tracks); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
+
+// replaces the data tracks of the graph by their stitched track, it keeps the channel and contrast of the first track
+// the scale factors are stored in the metadata of the new track
+func stitchDataTracks(canvas *graph.GraphCanvas, tracks function.Functions) error {
+	points := make([]function.Points, len(tracks))
+	for i, track := range tracks {
+		// masked points are not stitched
+		points[i] = track.GetUnmaskedData()
+	}
+	result, err := data.Stitch(points, data.DEFAULT_STITCH_TOLERANCE)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(tracks))
+	metadata := &function.Metadata{ImportTime: time.Now()}
+	if first := tracks[0].Metadata; first != nil {
+		metadata = first.Copy()
+		metadata.ImportTime = time.Now()
+	}
+	for i, track := range tracks {
+		names[i] = trackLabel(track, slices.Index(canvas.GetDataTracks(), track))
+		metadata.Set(fmt.Sprintf("scale %s", names[i]), fmt.Sprintf("%g ± %g", result.Scales[i], result.ScaleErrors[i]))
+	}
+	metadata.Name = "stitched " + strings.Join(names, ", ")
+
+	stitched := function.NewFunction(result.Points)
+	stitched.Metadata = metadata
+
+	channel := canvas.GetDataTrackChannel(tracks[0])
+	contrast := canvas.GetDataTrackSelection(tracks[0], contrastSelector)
+	for _, track := range tracks {
+		canvas.RemoveDataTrack(track)
+	}
+	canvas.AddDataTrack(stitched)
+	canvas.SetDataTrackChannel(stitched, channel)
+	canvas.SetDataTrackSelection(stitched, contrastSelector, contrast)
+
+	trigger.Recalc()
+	return nil
+}
@@ -13,11 +13,30 @@ type ConfigInformation struct {
 	Plot                      []PlotInformation      `json:"plot" xml:"plot"`
 	ParameterVersionIndicator []byte                 `json:"parameter_version" xml:"parameter_version"`
 	Parameter                 []ParameterInformation `json:"parameter" xml:"parameter"`
+	Settings                  []SettingInformation   `json:"settings" xml:"settings"`
+	// structure of the model the parameters belong to, missing in files of older versions
+	Structure *StructureInformation `json:"structure,omitempty" xml:"structure,omitempty"`
+}
+
+// StructureInformation describes the layer stack, spline profile and contrasts of a project
+// they define which parameters exist, so they are restored before the parameters are loaded
+type StructureInformation struct {
+	Layers       int      `json:"layers" xml:"layers"`
+	RepeatLayers int      `json:"repeat_layers" xml:"repeat_layers"`
+	Magnetic     bool     `json:"magnetic" xml:"magnetic"`
+	SplineKnots  int      `json:"spline_knots" xml:"spline_knots"`
+	Contrasts    []string `json:"contrasts,omitempty" xml:"contrasts,omitempty"`
 }
 
 type FunctionInformation struct {
-	Points function.Points `json:"points" xml:"points"`
-	Scope  function.Scope  `json:"scope" xml:"scope"`
+	Points   function.Points `json:"points" xml:"points"`
+	Scope    function.Scope  `json:"scope" xml:"scope"`
+	Channel  int             `json:"channel" xml:"channel"`
+	Contrast int             `json:"contrast" xml:"contrast"`
+	// origin of the data track, missing in files of older versions
+	Metadata *function.Metadata `json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// points excluded from fits, missing in files of older versions
+	Mask *function.Mask `json:"mask,omitempty" xml:"mask,omitempty"`
 }
 type PlotInformation struct {
 	Name       string                `json:"name" xml:"name"`
@@ -35,6 +54,11 @@ type ParameterInformation struct {
 	FieldMaximum string `json:"maximum" xml:"maximum"`
 }
 
+type SettingInformation struct {
+	Key   string `json:"key" xml:"key"`
+	Value string `json:"value" xml:"value"`
+}
+
 func DecodeJSONFromBytes(data []byte) (*ConfigInformation, error) {
 	var conf ConfigInformation
 	if err := json.Unmarshal(data, &conf); err != nil {
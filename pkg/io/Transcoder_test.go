@@ -0,0 +1,84 @@
+package io
+
+import (
+	"physicsGUI/pkg/function"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// encodes the metadata and mask of a data track and the model structure with every encoder and decodes it again
+func TestMetadataRoundTrip(t *testing.T) {
+	metadata := &function.Metadata{
+		Name:        "Ni on Si",
+		Source:      "/data/ni.ort",
+		ImportTime:  time.Date(2025, 3, 14, 15, 9, 26, 535000000, time.UTC),
+		Sample:      "Ni on Si",
+		Temperature: 295.5,
+		Instrument:  "amor",
+		Units:       "Qz (1/nm), R, sR, sQz",
+		Values: []function.MetadataValue{
+			{Key: "owner", Value: "someone"},
+			{Key: "probe", Value: "neutron"},
+		},
+	}
+	mask := &function.Mask{Ranges: []function.MaskRange{{Min: 0.1, Max: 0.2}}, Points: []float64{0.05}}
+	config := &ConfigInformation{
+		Plot: []PlotInformation{{
+			Name: "intensity",
+			DataTracks: []FunctionInformation{{
+				Points:   function.Points{{X: 0.01, Y: 1, Error: 0.1, Resolution: 0.001}},
+				Metadata: metadata,
+				Mask:     mask,
+			}},
+		}},
+		Structure: &StructureInformation{Layers: 4, RepeatLayers: 2, Magnetic: true, SplineKnots: 3, Contrasts: []string{"H2O", "D2O"}},
+	}
+
+	encoders := []struct {
+		name   string
+		encode func(*ConfigInformation) ([]byte, error)
+		decode func([]byte) (*ConfigInformation, error)
+	}{
+		{"json", EncodeJSONToBytes, DecodeJSONFromBytes},
+		{"xml", EncodeXMLToBytes, DecodeXMLFromBytes},
+		{"gob", EncodeGOBToBytes, DecodeGOBFromBytes},
+	}
+
+	for _, encoder := range encoders {
+		encoded, err := encoder.encode(config)
+		if err != nil {
+			t.Fatalf("%s: %v", encoder.name, err)
+		}
+		decoded, err := encoder.decode(encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", encoder.name, err)
+		}
+		if len(decoded.Plot) != 1 || len(decoded.Plot[0].DataTracks) != 1 {
+			t.Fatalf("%s: expected one plot with one data track got %v", encoder.name, decoded.Plot)
+		}
+
+		track := decoded.Plot[0].DataTracks[0]
+		if track.Metadata == nil {
+			t.Fatalf("%s: metadata missing", encoder.name)
+		}
+		if !track.Metadata.ImportTime.Equal(metadata.ImportTime) {
+			t.Errorf("%s: expected import time %v got %v", encoder.name, metadata.ImportTime, track.Metadata.ImportTime)
+		}
+		// the location of the time is not part of the comparison
+		decodedMetadata := *track.Metadata
+		decodedMetadata.ImportTime = metadata.ImportTime
+		if !reflect.DeepEqual(&decodedMetadata, metadata) {
+			t.Errorf("%s: expected metadata %+v got %+v", encoder.name, *metadata, decodedMetadata)
+		}
+		if !reflect.DeepEqual(track.Mask, mask) {
+			t.Errorf("%s: expected mask %+v got %+v", encoder.name, *mask, track.Mask)
+		}
+		if len(track.Points) != 1 || *track.Points[0] != *config.Plot[0].DataTracks[0].Points[0] {
+			t.Errorf("%s: expected points %v got %v", encoder.name, config.Plot[0].DataTracks[0].Points, track.Points)
+		}
+		if !reflect.DeepEqual(decoded.Structure, config.Structure) {
+			t.Errorf("%s: expected structure %+v got %+v", encoder.name, *config.Structure, decoded.Structure)
+		}
+	}
+}
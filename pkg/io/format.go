@@ -0,0 +1,144 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ProjectFormat is a file format of saved projects (parameters, plots and settings)
+type ProjectFormat struct {
+	Name string
+	// file extensions including the dot (e.g. ".json"), compared case insensitive
+	Extensions []string
+	// optional, reports whether the content is in this format, used when loading files with an unknown extension
+	Sniff  func(data []byte) bool
+	Decode func(data []byte) (*ConfigInformation, error)
+	Encode func(config *ConfigInformation) ([]byte, error)
+}
+
+// ExportFormat is a file format the points of the graphs are exported to
+type ExportFormat struct {
+	Name string
+	// file extensions including the dot (e.g. ".csv"), compared case insensitive
+	Extensions []string
+	Export     func(export ExportInformation) ([]byte, error)
+}
+
+// ExportInformation is everything an export format can write
+type ExportInformation struct {
+	// points of the functions and data tracks of every graph
+	Graphs []PointsExport
+	// data tracks and reflectivity curves with their metadata
+	Datasets []ORTDataset
+}
+
+var gobProjectFormat = &ProjectFormat{
+	Name:   "GOB",
+	Decode: DecodeGOBFromBytes,
+	Encode: EncodeGOBToBytes,
+}
+
+// registered project formats, binary encoding is the fallback for unknown files
+var projectFormats = []*ProjectFormat{
+	{
+		Name:       "JSON",
+		Extensions: []string{".json"},
+		Sniff:      func(data []byte) bool { return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) },
+		Decode:     DecodeJSONFromBytes,
+		Encode:     EncodeJSONToBytes,
+	},
+	{
+		Name:       "XML",
+		Extensions: []string{".xml"},
+		Sniff:      func(data []byte) bool { return bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) },
+		Decode:     DecodeXMLFromBytes,
+		Encode:     EncodeXMLToBytes,
+	},
+	gobProjectFormat,
+}
+
+var csvExportFormat = &ExportFormat{
+	Name:       "CSV",
+	Extensions: []string{".csv"},
+	Export:     func(export ExportInformation) ([]byte, error) { return ExportCSVToFile(export.Graphs) },
+}
+
+// registered export formats, csv is the fallback for unknown extensions
+var exportFormats = []*ExportFormat{
+	{
+		Name:       "ORSO",
+		Extensions: []string{".ort"},
+		Export:     func(export ExportInformation) ([]byte, error) { return ExportORTToFile(export.Datasets) },
+	},
+	{
+		Name:       "XML",
+		Extensions: []string{".xml"},
+		Export:     func(export ExportInformation) ([]byte, error) { return ExportXMLToFile(export.Graphs) },
+	},
+	{
+		Name:       "JSON",
+		Extensions: []string{".json"},
+		Export:     func(export ExportInformation) ([]byte, error) { return ExportJSONToFile(export.Graphs) },
+	},
+	csvExportFormat,
+}
+
+// RegisterProjectFormat adds a project format, the names of the formats are unique
+func RegisterProjectFormat(format *ProjectFormat) error {
+	if format == nil || format.Name == "" || format.Decode == nil || format.Encode == nil {
+		return errors.New("project format needs a name, a decode and an encode function")
+	}
+	if slices.ContainsFunc(projectFormats, func(f *ProjectFormat) bool { return f.Name == format.Name }) {
+		return fmt.Errorf("project format '%s' already registered", format.Name)
+	}
+	projectFormats = append(projectFormats, format)
+	return nil
+}
+
+// RegisterExportFormat adds an export format, the names of the formats are unique
+func RegisterExportFormat(format *ExportFormat) error {
+	if format == nil || format.Name == "" || format.Export == nil {
+		return errors.New("export format needs a name and an export function")
+	}
+	if slices.ContainsFunc(exportFormats, func(f *ExportFormat) bool { return f.Name == format.Name }) {
+		return fmt.Errorf("export format '%s' already registered", format.Name)
+	}
+	exportFormats = append(exportFormats, format)
+	return nil
+}
+
+// FindProjectFormat returns the format of a project file, the first format with the extension of the filename,
+// otherwise the first format recognizing the content (data is nil when saving) and GOB if none matches
+func FindProjectFormat(filename string, data []byte) *ProjectFormat {
+	for _, format := range projectFormats {
+		if hasExtension(format.Extensions, filename) {
+			return format
+		}
+	}
+	for _, format := range projectFormats {
+		if data != nil && format.Sniff != nil && format.Sniff(data) {
+			return format
+		}
+	}
+	return gobProjectFormat
+}
+
+// FindExportFormat returns the first export format with the extension of the filename, CSV if none matches
+func FindExportFormat(filename string) *ExportFormat {
+	for _, format := range exportFormats {
+		if hasExtension(format.Extensions, filename) {
+			return format
+		}
+	}
+	return csvExportFormat
+}
+
+// reports whether the filename has one of the extensions
+func hasExtension(extensions []string, filename string) bool {
+	extension := filepath.Ext(filename)
+	return slices.ContainsFunc(extensions, func(e string) bool { return strings.EqualFold(e, extension) })
+}
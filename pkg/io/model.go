@@ -0,0 +1,219 @@
+package io
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/physics"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelDefinition describes the parameters and graphs of an experiment
+type ModelDefinition struct {
+	// number of layers between ambient medium and substrate
+	Layers int `json:"layers" yaml:"layers"`
+
+	// layers and substrate have a magnetic sld and angle (polarised neutron reflectivity)
+	Magnetic bool `json:"magnetic" yaml:"magnetic"`
+
+	// number of layers of the repeat unit (superlattice) between the layers and the substrate and its number of periods
+	RepeatLayers int `json:"repeat_layers,omitempty" yaml:"repeat_layers,omitempty"`
+	Repetitions  int `json:"repetitions,omitempty" yaml:"repetitions,omitempty"`
+
+	// number of knots of the free-form spline profile (used by the "Spline" profile mode), at least MIN_SPLINE_KNOTS
+	SplineKnots int `json:"spline_knots,omitempty" yaml:"spline_knots,omitempty"`
+
+	// parameter definitions, entries for parameters of the layer stack or the general group
+	// override their defaults, all other entries create additional parameters
+	Parameters []ParameterDefinition `json:"parameters" yaml:"parameters"`
+
+	// graphs shown in the gui in the given order
+	Graphs []GraphDefinition `json:"graphs" yaml:"graphs"`
+
+	// number of columns the graphs are arranged in
+	Columns int `json:"columns" yaml:"columns"`
+
+	// optional names of contrasts (e.g. H2O, D2O) fitted together, every contrast has its own model instance
+	Contrasts []string `json:"contrasts,omitempty" yaml:"contrasts,omitempty"`
+
+	// parameters every contrast has its own copy of (e.g. solvent eden, scaling), all others are shared
+	LocalParameters []ParameterReference `json:"local_parameters,omitempty" yaml:"local_parameters,omitempty"`
+
+	// eden parameters defined by a chemical formula and a mass density, the density is shown (and fitted) instead of the eden
+	Materials []MaterialDefinition `json:"materials,omitempty" yaml:"materials,omitempty"`
+}
+
+type ParameterReference struct {
+	Group string `json:"group" yaml:"group"`
+	Name  string `json:"name" yaml:"name"`
+}
+
+type ParameterDefinition struct {
+	Group   string   `json:"group" yaml:"group"`
+	Name    string   `json:"name" yaml:"name"`
+	Default float64  `json:"default" yaml:"default"`
+	Min     *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	Fit     bool     `json:"fit" yaml:"fit"`
+}
+
+// MaterialDefinition defines the eden parameter (group, name) by a chemical formula (e.g. SiO2)
+// and a mass density in g/cm³, min, max and fit belong to the density
+type MaterialDefinition struct {
+	Group   string   `json:"group" yaml:"group"`
+	Name    string   `json:"name" yaml:"name"`
+	Formula string   `json:"formula" yaml:"formula"`
+	Density float64  `json:"density" yaml:"density"`
+	Min     *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	Fit     bool     `json:"fit" yaml:"fit"`
+}
+
+type GraphDefinition struct {
+	Id    string `json:"id" yaml:"id"`
+	Title string `json:"title" yaml:"title"`
+	IsLog bool   `json:"log" yaml:"log"`
+
+	// display transform selected initially for reflectivity graphs (R, R·q⁴, R/R_F, log R)
+	Transform string `json:"transform,omitempty" yaml:"transform,omitempty"`
+
+	// deprecated: selects the R·q⁴ transform if no transform is given
+	AdaptDraw bool `json:"adapt_draw,omitempty" yaml:"adapt_draw,omitempty"`
+
+	// identifiers of the physics functions shown in the graph
+	Functions []string `json:"functions" yaml:"functions"`
+
+	// optional names of the functions, data tracks can be assigned to one of them (e.g. spin channels)
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// optional x-range to plot
+	DisplayMin *float64 `json:"display_min,omitempty" yaml:"display_min,omitempty"`
+	DisplayMax *float64 `json:"display_max,omitempty" yaml:"display_max,omitempty"`
+}
+
+// number of spline knots of model definitions without spline_knots, the spline interpolation needs at least two
+const MIN_SPLINE_KNOTS = 2
+
+// groups of the eden parameters a material can define (layers, repeat unit and spline knots)
+var materialGroups = []string{physics.EDEN_GROUP, physics.REPEAT_GROUP, physics.SPLINE_GROUP}
+
+// decodes a model definition, files with the ".json" extension are decoded as json all others as yaml
+func DecodeModelDefinition(data []byte, extension string) (*ModelDefinition, error) {
+	model := ModelDefinition{SplineKnots: MIN_SPLINE_KNOTS}
+
+	if strings.EqualFold(".json", extension) {
+		if err := json.Unmarshal(data, &model); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &model); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+// checks the model definition for missing or duplicate entries
+func (m *ModelDefinition) Validate() error {
+	if m.Layers < 0 {
+		return fmt.Errorf("model definition: negative number of layers %d", m.Layers)
+	}
+	if m.RepeatLayers < 0 || m.Repetitions < 0 {
+		return fmt.Errorf("model definition: negative repeat unit %d×%d", m.RepeatLayers, m.Repetitions)
+	}
+	if m.SplineKnots < MIN_SPLINE_KNOTS {
+		return fmt.Errorf("model definition: %d spline knots, at least %d needed", m.SplineKnots, MIN_SPLINE_KNOTS)
+	}
+	if len(m.Graphs) == 0 {
+		return errors.New("model definition: no graphs defined")
+	}
+
+	params := make(map[string]bool)
+	for _, p := range m.Parameters {
+		if p.Group == "" || p.Name == "" {
+			return fmt.Errorf("model definition: parameter '%s/%s' needs a group and a name", p.Group, p.Name)
+		}
+		if params[p.Group+"/"+p.Name] {
+			return fmt.Errorf("model definition: parameter '%s/%s' defined twice", p.Group, p.Name)
+		}
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return fmt.Errorf("model definition: parameter '%s/%s' has a minimum larger than its maximum", p.Group, p.Name)
+		}
+		params[p.Group+"/"+p.Name] = true
+	}
+
+	graphs := make(map[string]bool)
+	for _, g := range m.Graphs {
+		if g.Id == "" {
+			return errors.New("model definition: graph without id")
+		}
+		if graphs[g.Id] {
+			return fmt.Errorf("model definition: graph '%s' defined twice", g.Id)
+		}
+		if len(g.Functions) == 0 {
+			return fmt.Errorf("model definition: graph '%s' has no functions", g.Id)
+		}
+		if len(g.Channels) > 0 && len(g.Channels) != len(g.Functions) {
+			return fmt.Errorf("model definition: graph '%s' needs one channel name per function", g.Id)
+		}
+		graphs[g.Id] = true
+	}
+
+	contrasts := make(map[string]bool)
+	for _, c := range m.Contrasts {
+		if c == "" {
+			return errors.New("model definition: contrast without name")
+		}
+		if contrasts[c] {
+			return fmt.Errorf("model definition: contrast '%s' defined twice", c)
+		}
+		contrasts[c] = true
+	}
+
+	local := make(map[string]bool)
+	for _, p := range m.LocalParameters {
+		if len(m.Contrasts) == 0 {
+			return errors.New("model definition: local parameters need contrasts")
+		}
+		if p.Group == "" || p.Name == "" {
+			return fmt.Errorf("model definition: local parameter '%s/%s' needs a group and a name", p.Group, p.Name)
+		}
+		if local[p.Group+"/"+p.Name] {
+			return fmt.Errorf("model definition: local parameter '%s/%s' defined twice", p.Group, p.Name)
+		}
+		local[p.Group+"/"+p.Name] = true
+	}
+
+	materials := make(map[string]bool)
+	for _, material := range m.Materials {
+		if material.Group == "" || material.Name == "" || material.Formula == "" {
+			return fmt.Errorf("model definition: material '%s/%s' needs a group, a name and a formula", material.Group, material.Name)
+		}
+		if !slices.Contains(materialGroups, material.Group) {
+			return fmt.Errorf("model definition: material '%s/%s' is not in an eden group %v", material.Group, material.Name, materialGroups)
+		}
+		if materials[material.Group+"/"+material.Name] {
+			return fmt.Errorf("model definition: material '%s/%s' defined twice", material.Group, material.Name)
+		}
+		if material.Density < 0 {
+			return fmt.Errorf("model definition: material '%s/%s' has a negative density", material.Group, material.Name)
+		}
+		if material.Min != nil && material.Max != nil && *material.Min > *material.Max {
+			return fmt.Errorf("model definition: material '%s/%s' has a minimum larger than its maximum", material.Group, material.Name)
+		}
+		if local[material.Group+"/"+material.Name] {
+			return fmt.Errorf("model definition: material '%s/%s' can not be a local parameter", material.Group, material.Name)
+		}
+		materials[material.Group+"/"+material.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package io
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// returns a valid model definition which is changed by the test cases
+func validModelDefinition() *ModelDefinition {
+	zero, one := 0.0, 1.0
+	return &ModelDefinition{
+		Layers:       2,
+		RepeatLayers: 2,
+		Repetitions:  10,
+		SplineKnots:  MIN_SPLINE_KNOTS,
+		Parameters: []ParameterDefinition{
+			{Group: "eden", Name: "Eden 1", Default: 0.3, Min: &zero, Max: &one, Fit: true},
+			{Group: "general", Name: "scaling", Default: 1},
+		},
+		Graphs: []GraphDefinition{
+			{Id: "eden", Title: "Edensity Graph", Functions: []string{"eden"}},
+			{Id: "intensity", Title: "Intensity Graph", IsLog: true, Transform: "R·q⁴", Functions: []string{"intensity", "born"}, DisplayMin: &zero},
+		},
+		Columns:         2,
+		Contrasts:       []string{"H2O", "D2O"},
+		LocalParameters: []ParameterReference{{Group: "general", Name: "scaling"}},
+		Materials:       []MaterialDefinition{{Group: "eden", Name: "Eden b", Formula: "Si", Density: 2.329, Min: &zero, Max: &one}},
+	}
+}
+
+func TestModelDefinitionValidate(t *testing.T) {
+	if err := validModelDefinition().Validate(); err != nil {
+		t.Fatalf("valid model definition: %v", err)
+	}
+
+	for name, change := range map[string]func(m *ModelDefinition){
+		"negative layers":         func(m *ModelDefinition) { m.Layers = -1 },
+		"negative repetitions":    func(m *ModelDefinition) { m.Repetitions = -1 },
+		"single spline knot":      func(m *ModelDefinition) { m.SplineKnots = 1 },
+		"no graphs":               func(m *ModelDefinition) { m.Graphs = nil },
+		"missing parameter group": func(m *ModelDefinition) { m.Parameters[0].Group = "" },
+		"missing parameter name":  func(m *ModelDefinition) { m.Parameters[0].Name = "" },
+		"duplicate parameter":     func(m *ModelDefinition) { m.Parameters[1] = m.Parameters[0] },
+		"parameter min > max": func(m *ModelDefinition) {
+			m.Parameters[0].Min, m.Parameters[0].Max = m.Parameters[0].Max, m.Parameters[0].Min
+		},
+		"missing graph id":          func(m *ModelDefinition) { m.Graphs[1].Id = "" },
+		"duplicate graph id":        func(m *ModelDefinition) { m.Graphs[1].Id = "eden" },
+		"graph without functions":   func(m *ModelDefinition) { m.Graphs[0].Functions = nil },
+		"wrong channel count":       func(m *ModelDefinition) { m.Graphs[1].Channels = []string{"++"} },
+		"missing contrast name":     func(m *ModelDefinition) { m.Contrasts[1] = "" },
+		"duplicate contrast":        func(m *ModelDefinition) { m.Contrasts[1] = "H2O" },
+		"local without contrasts":   func(m *ModelDefinition) { m.Contrasts = nil },
+		"missing local group":       func(m *ModelDefinition) { m.LocalParameters[0].Group = "" },
+		"duplicate local parameter": func(m *ModelDefinition) { m.LocalParameters = append(m.LocalParameters, m.LocalParameters[0]) },
+		"missing material formula":  func(m *ModelDefinition) { m.Materials[0].Formula = "" },
+		"unknown material group":    func(m *ModelDefinition) { m.Materials[0].Group = "thick" },
+		"duplicate material":        func(m *ModelDefinition) { m.Materials = append(m.Materials, m.Materials[0]) },
+		"negative density":          func(m *ModelDefinition) { m.Materials[0].Density = -1 },
+		"material min > max": func(m *ModelDefinition) {
+			m.Materials[0].Min, m.Materials[0].Max = m.Materials[0].Max, m.Materials[0].Min
+		},
+		"local material": func(m *ModelDefinition) {
+			m.LocalParameters = append(m.LocalParameters, ParameterReference{Group: "eden", Name: "Eden b"})
+		},
+	} {
+		m := validModelDefinition()
+		change(m)
+		if err := m.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// the built-in model of the gui has to be a valid model definition
+func TestDefaultModelDefinition(t *testing.T) {
+	data, err := os.ReadFile("../gui/default_model.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := DecodeModelDefinition(data, ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Layers != 2 || len(m.Graphs) == 0 || m.SplineKnots < MIN_SPLINE_KNOTS {
+		t.Errorf("unexpected default model: %+v", m)
+	}
+}
+
+// encodes a model definition as yaml and json and decodes it again
+func TestModelDefinitionRoundTrip(t *testing.T) {
+	model := validModelDefinition()
+
+	for _, c := range []struct {
+		extension string
+		encode    func(any) ([]byte, error)
+	}{
+		{".yaml", yaml.Marshal},
+		{".json", json.Marshal},
+	} {
+		data, err := c.encode(model)
+		if err != nil {
+			t.Fatalf("%s: %v", c.extension, err)
+		}
+
+		decoded, err := DecodeModelDefinition(data, strings.ToUpper(c.extension))
+		if err != nil {
+			t.Fatalf("%s: %v", c.extension, err)
+		}
+		if !reflect.DeepEqual(model, decoded) {
+			t.Errorf("%s: model definition changed\n%+v\n%+v", c.extension, model, decoded)
+		}
+	}
+
+	// spline knots default to the minimum
+	decoded, err := DecodeModelDefinition([]byte(`{"layers": 1, "graphs": [{"id": "eden", "functions": ["eden"]}]}`), ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SplineKnots != MIN_SPLINE_KNOTS {
+		t.Errorf("expected %d spline knots got %d", MIN_SPLINE_KNOTS, decoded.SplineKnots)
+	}
+}
@@ -0,0 +1,148 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/function"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// first line of ORSO reflectivity text files (.ort)
+const ortMagic = "# # ORSO reflectivity data file | 1.0 standard | YAML encoding | https://www.reflectometry.org/"
+
+// ORTDataset is a dataset of an exported ORSO file, measured data or a model curve
+type ORTDataset struct {
+	// unique name of the dataset in the file
+	Name string
+	// optional sample, temperature (K), instrument, probe, owner and original units (keys as in data.Dataset metadata)
+	Metadata map[string]string
+	Points   function.Points
+}
+
+type ortHeader struct {
+	DataSource ortDataSource `yaml:"data_source"`
+	Reduction  ortReduction  `yaml:"reduction"`
+	DataSet    string        `yaml:"data_set"`
+	// units of the columns of the imported file, the exported columns are always the ones of ortColumns
+	OriginalUnits string      `yaml:"original_units,omitempty"`
+	Columns       []ortColumn `yaml:"columns"`
+}
+
+// header of the following datasets, only the changes to the first header
+type ortChanges struct {
+	DataSet       string        `yaml:"data_set"`
+	DataSource    ortDataSource `yaml:"data_source"`
+	OriginalUnits string        `yaml:"original_units,omitempty"`
+}
+
+type ortDataSource struct {
+	Owner      ortName       `yaml:"owner"`
+	Experiment ortExperiment `yaml:"experiment"`
+	Sample     ortSample     `yaml:"sample"`
+}
+
+type ortName struct {
+	Name string `yaml:"name"`
+}
+
+type ortSample struct {
+	Name       string               `yaml:"name"`
+	Parameters *ortSampleParameters `yaml:"sample_parameters,omitempty"`
+}
+
+type ortSampleParameters struct {
+	Temperature ortValue `yaml:"temperature"`
+}
+
+type ortValue struct {
+	Magnitude float64 `yaml:"magnitude"`
+	Unit      string  `yaml:"unit"`
+}
+
+type ortExperiment struct {
+	Instrument string `yaml:"instrument"`
+	Probe      string `yaml:"probe"`
+}
+
+type ortReduction struct {
+	Software ortName `yaml:"software"`
+}
+
+type ortColumn struct {
+	Name      string `yaml:"name,omitempty"`
+	Unit      string `yaml:"unit,omitempty"`
+	ErrorOf   string `yaml:"error_of,omitempty"`
+	ErrorType string `yaml:"error_type,omitempty"`
+	ValueIs   string `yaml:"value_is,omitempty"`
+}
+
+// columns of the exported datasets, errors are standard deviations
+var ortColumns = []ortColumn{
+	{Name: "Qz", Unit: "1/angstrom"},
+	{Name: "R"},
+	{ErrorOf: "R", ErrorType: "uncertainty", ValueIs: "sigma"},
+	{ErrorOf: "Qz", ErrorType: "resolution", ValueIs: "sigma"},
+}
+
+// ExportORTToFile encodes the datasets as ORSO reflectivity text file (.ort)
+// the first dataset carries the full header, the following ones only their name and data source
+func ExportORTToFile(datasets []ORTDataset) ([]byte, error) {
+	if len(datasets) == 0 {
+		return nil, errors.New("orso export: no datasets")
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(ortMagic + "\n")
+
+	names := make(map[string]bool)
+	for i, d := range datasets {
+		if names[d.Name] {
+			return nil, fmt.Errorf("orso export: duplicate dataset '%s'", d.Name)
+		}
+		names[d.Name] = true
+
+		source := ortDataSource{
+			Owner:      ortName{Name: d.Metadata["owner"]},
+			Experiment: ortExperiment{Instrument: d.Metadata["instrument"], Probe: d.Metadata["probe"]},
+			Sample:     ortSample{Name: d.Metadata["sample"]},
+		}
+		if value, ok := d.Metadata["temperature"]; ok {
+			temperature, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("orso export: dataset '%s' has an invalid temperature '%s'", d.Name, value)
+			}
+			source.Sample.Parameters = &ortSampleParameters{Temperature: ortValue{Magnitude: temperature, Unit: "K"}}
+		}
+
+		var header any = ortChanges{DataSet: d.Name, DataSource: source, OriginalUnits: d.Metadata["units"]}
+		if i == 0 {
+			header = ortHeader{
+				DataSource:    source,
+				Reduction:     ortReduction{Software: ortName{Name: "SPIRIT"}},
+				DataSet:       d.Name,
+				OriginalUnits: d.Metadata["units"],
+				Columns:       ortColumns,
+			}
+		}
+		var encoded bytes.Buffer
+		encoder := yaml.NewEncoder(&encoded)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(header); err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(strings.TrimRight(encoded.String(), "\n"), "\n") {
+			buffer.WriteString("# " + line + "\n")
+		}
+		buffer.WriteString("# # Qz (1/angstrom)    R    sR    sQz\n")
+
+		for _, p := range d.Points {
+			buffer.WriteString(fmt.Sprintf("%.10e %.10e %.10e %.10e\n", p.X, p.Y, p.Error, p.Resolution))
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package io
+
+import (
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"testing"
+)
+
+// exports two datasets and imports them again
+func TestORTRoundTrip(t *testing.T) {
+	datasets := []ORTDataset{
+		{
+			Name:     "data",
+			Metadata: map[string]string{"sample": "Ni on Si", "instrument": "amor", "probe": "neutron", "owner": "someone", "temperature": "295.5", "units": "Qz (1/nm), R"},
+			Points: function.Points{
+				{X: 0.01, Y: 1, Error: 0.1, Resolution: 0.001},
+				{X: 0.02, Y: 0.5, Error: 0.05, Resolution: 0.002},
+			},
+		},
+		{
+			Name:     "model",
+			Metadata: map[string]string{"sample": "Ni on Si", "instrument": "amor"},
+			Points: function.Points{
+				{X: 0.015, Y: 0.75},
+			},
+		},
+	}
+
+	encoded, err := ExportORTToFile(datasets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := data.ParseORSO(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(datasets) {
+		t.Fatalf("expected %d datasets got %d", len(datasets), len(decoded))
+	}
+
+	for i, d := range datasets {
+		metadata := decoded[i].Metadata
+		if metadata[data.METADATA_DATASET] != d.Name {
+			t.Errorf("expected dataset '%s' got '%s'", d.Name, metadata[data.METADATA_DATASET])
+		}
+		if metadata[data.METADATA_SAMPLE] != d.Metadata["sample"] || metadata[data.METADATA_INSTRUMENT] != d.Metadata["instrument"] {
+			t.Errorf("%s: expected sample and instrument %v got %v", d.Name, d.Metadata, metadata)
+		}
+		if temperature, ok := d.Metadata["temperature"]; ok && metadata[data.METADATA_TEMPERATURE] != temperature {
+			t.Errorf("%s: expected temperature %s got %s", d.Name, temperature, metadata[data.METADATA_TEMPERATURE])
+		}
+
+		if len(decoded[i].Points) != len(d.Points) {
+			t.Fatalf("%s: expected %d points got %d", d.Name, len(d.Points), len(decoded[i].Points))
+		}
+		for j, p := range d.Points {
+			if *decoded[i].Points[j] != *p {
+				t.Errorf("%s: expected point %v got %v", d.Name, *p, *decoded[i].Points[j])
+			}
+		}
+	}
+}
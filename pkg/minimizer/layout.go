@@ -0,0 +1,56 @@
+package minimizer
+
+import "fmt"
+
+// SharedLayout maps the parameters passed to the minimizer to the parameters of several model instances (e.g. contrasts)
+// parameters shared by the instances appear once, local parameters once per instance
+type SharedLayout struct {
+	// number of parameters passed to the minimizer
+	Count int
+
+	// index in the minimizer parameters of every model parameter per instance
+	Indices [][]int
+}
+
+// NewSharedLayout creates the layout of the model parameters of every instance
+// equal parameters are shared, the returned parameters are the distinct ones in the order of their first occurrence
+func NewSharedLayout[T comparable](instances [][]T) (*SharedLayout, []T) {
+	layout := &SharedLayout{
+		Indices: make([][]int, len(instances)),
+	}
+
+	parameters := make([]T, 0)
+	index := make(map[T]int)
+	for c, instance := range instances {
+		layout.Indices[c] = make([]int, len(instance))
+		for j, p := range instance {
+			i, ok := index[p]
+			if !ok {
+				i = len(parameters)
+				index[p] = i
+				parameters = append(parameters, p)
+			}
+			layout.Indices[c][j] = i
+		}
+	}
+	layout.Count = len(parameters)
+
+	return layout, parameters
+}
+
+// Values returns the model parameter values of an instance
+// the parameters need to match the layout, it changes when the model parameters are edited
+func (l *SharedLayout) Values(params []float64, instance int) ([]float64, error) {
+	if len(params) != l.Count {
+		return nil, fmt.Errorf("got %d parameters but the fit layout has %d", len(params), l.Count)
+	}
+	if instance < 0 || instance >= len(l.Indices) {
+		return nil, fmt.Errorf("model instance %d not in the fit layout", instance)
+	}
+
+	values := make([]float64, len(l.Indices[instance]))
+	for i, index := range l.Indices[instance] {
+		values[i] = params[index]
+	}
+	return values, nil
+}
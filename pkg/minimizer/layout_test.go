@@ -0,0 +1,44 @@
+package minimizer
+
+import (
+	"slices"
+	"testing"
+)
+
+// two contrasts sharing all parameters except the scaling
+func TestSharedLayout(t *testing.T) {
+	layout, parameters := NewSharedLayout([][]string{
+		{"eden", "thickness", "scaling H2O"},
+		{"eden", "thickness", "scaling D2O"},
+	})
+
+	if !slices.Equal(parameters, []string{"eden", "thickness", "scaling H2O", "scaling D2O"}) || layout.Count != 4 {
+		t.Fatalf("unexpected fit parameters %v", parameters)
+	}
+
+	params := []float64{0.3, 20, 0.9, 1.1}
+	for contrast, expected := range [][]float64{{0.3, 20, 0.9}, {0.3, 20, 1.1}} {
+		values, err := layout.Values(params, contrast)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(values, expected) {
+			t.Errorf("contrast %d: expected %v got %v", contrast, expected, values)
+		}
+	}
+
+	// the parameters of an outdated layout (e.g. after adding a layer) are rejected
+	for _, c := range []struct {
+		params   []float64
+		contrast int
+	}{
+		{params[:3], 0},
+		{append(params, 1), 1},
+		{params, 2},
+		{params, -1},
+	} {
+		if _, err := layout.Values(c.params, c.contrast); err == nil {
+			t.Errorf("expected an error for %d parameters of contrast %d", len(c.params), c.contrast)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// calculates reflectivity using the Abeles matrix formalism
+//
+// qzaxis: momentum transfer values
+//
+// deltaz: layer thicknesses
+//
+// sld: complex scattering length densities, a negative imaginary part describes absorption
+func CalculateAbelesReflectivity(qzaxis []float64, deltaz float64, sld []complex128) []float64 {
+	return CalculateStackAbelesReflectivity(qzaxis, NewSlicedStack(deltaz, sld))
+}
+
+// calculates reflectivity of a stack using the Abeles matrix formalism
+//
+// the amplitudes of the down- and upwards travelling waves at the top of medium j-1 are given by
+// M_j = [[exp(-i k d), r exp(-i k d)], [r exp(i k d), exp(i k d)]] times the amplitudes at the top of medium j,
+// where k and d belong to medium j-1 (d = 0 for the ambient medium) and r is the fresnel coefficient of the interface.
+// the reflectivity follows from the product of all matrices as |M_10 / M_00|^2
+func CalculateStackAbelesReflectivity(qzaxis []float64, stack *Stack) []float64 {
+	ci := complex(0, 1.0)
+
+	nmedia := len(stack.SLD)
+	refl := make([]float64, len(qzaxis))
+	if nmedia < 2 {
+		return refl
+	}
+
+	for iq, q := range qzaxis {
+		k := stack.waveVectors(q / 2.0)
+
+		// total matrix of all interfaces, starting with the identity
+		m00, m01, m10, m11 := complex(1, 0), complex(0, 0), complex(0, 0), complex(1, 0)
+
+		for j := 1; j < nmedia; j++ {
+			r := stack.fresnel(k, j-1)
+
+			// phase of the medium above the interface
+			down, up := complex(1, 0), complex(1, 0)
+			if j > 1 {
+				up = cmplx.Exp(ci * k[j-1] * complex(stack.Thickness[j-2], 0))
+				down = 1 / up
+			}
+
+			// M = M * M_j
+			m00, m01, m10, m11 = m00*down+m01*r*up, m00*r*down+m01*up, m10*down+m11*r*up, m10*r*down+m11*up
+		}
+
+		refl[iq] = math.Pow(cmplx.Abs(m10/m00), 2)
+	}
+
+	return refl
+}
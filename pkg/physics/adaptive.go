@@ -0,0 +1,102 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+)
+
+const (
+	// number of slices per roughness near an interface
+	SLICES_PER_SIGMA = 4
+	// range around an interface in units of its roughness which is refined
+	REFINEMENT_RANGE = 4.0
+	// lower bound of the slice thickness in angstrom
+	MIN_SLICE = 0.05
+	// upper bound of the number of slices, protects against huge thicknesses
+	MAX_SLICES = 100000
+)
+
+// GetAdaptiveZAxis returns a z axis which is refined near the interfaces according to their roughness
+// and coarsened to maxSlice in flat regions
+// - d array with the d values {d_1,d_2,...,d_n} (Thickness)
+// - sigma array with sigma values {sigma_a1,sigma_12,...,sigma_nb} (Roughness)
+// - maxSlice is the maximum distance between two z values
+func GetAdaptiveZAxis(d []float64, sigma []float64, maxSlice float64) ([]float64, error) {
+	if len(sigma) != len(d)+1 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality roughness %d/thickness %d", len(sigma), len(d))
+	}
+	if !(maxSlice > 0) {
+		return nil, fmt.Errorf("maximum slice thickness needs to be positive: %f", maxSlice)
+	}
+
+	// refined regions around the interfaces
+	z := interfacePositions(d)
+	lower := make([]float64, len(z))
+	upper := make([]float64, len(z))
+	step := make([]float64, len(z))
+	for i := range z {
+		width := REFINEMENT_RANGE * math.Abs(sigma[i])
+		lower[i] = z[i] - width
+		upper[i] = z[i] + width
+		step[i] = max(math.Abs(sigma[i])/SLICES_PER_SIGMA, MIN_SLICE)
+	}
+
+	// ambient medium and substrate get one slice outside the refined regions
+	start := lower[0] - maxSlice
+	end := upper[0] + maxSlice
+	for i := range z {
+		start = min(start, lower[i]-maxSlice)
+		end = max(end, upper[i]+maxSlice)
+	}
+
+	zAxis := make([]float64, 0)
+	for zi := start; zi < end; {
+		if len(zAxis) >= MAX_SLICES {
+			return nil, fmt.Errorf("adaptive z axis needs more than %d slices", MAX_SLICES)
+		}
+		zAxis = append(zAxis, zi)
+
+		h := maxSlice
+		for i := range z {
+			if zi >= lower[i] && zi < upper[i] {
+				h = min(h, step[i])
+			} else if lower[i] > zi {
+				// do not step over the beginning of a refined region
+				h = min(h, lower[i]-zi)
+			}
+		}
+		zi += h
+	}
+	zAxis = append(zAxis, end)
+
+	return zAxis, nil
+}
+
+// GetAdaptiveEdensities returns the eden profile of GetEdensities on an adaptive z axis (see GetAdaptiveZAxis)
+func GetAdaptiveEdensities(eden []float64, d []float64, sigma []float64, maxSlice float64) (function.Points, error) {
+	if len(eden) != len(d)+2 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality edensities %d/thickness %d", len(eden), len(d))
+	}
+
+	zAxis, err := GetAdaptiveZAxis(d, sigma, maxSlice)
+	if err != nil {
+		return nil, err
+	}
+
+	return getErfProfileOnAxis(zAxis, eden, d, sigma), nil
+}
+
+// GetAdaptiveAbsorptions returns the absorption profile of GetAbsorptions on an adaptive z axis (see GetAdaptiveZAxis)
+func GetAdaptiveAbsorptions(absorption []float64, d []float64, sigma []float64, maxSlice float64) (function.Points, error) {
+	if len(absorption) != len(d)+2 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality absorptions %d/thickness %d", len(absorption), len(d))
+	}
+
+	zAxis, err := GetAdaptiveZAxis(d, sigma, maxSlice)
+	if err != nil {
+		return nil, err
+	}
+
+	return getErfProfileOnAxis(zAxis, absorption, d, sigma), nil
+}
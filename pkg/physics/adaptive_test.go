@@ -0,0 +1,128 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// the spacing of the adaptive z axis is bounded by the maximum slice and refined near interfaces
+func TestAdaptiveZAxisSpacing(t *testing.T) {
+	maxSlice := 2.0
+	zAxis, err := GetAdaptiveZAxis(testD, testSigma, maxSlice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z := interfacePositions(testD)
+	for i := 1; i < len(zAxis); i++ {
+		dz := zAxis[i] - zAxis[i-1]
+		if dz <= 0 || dz > maxSlice+1e-12 {
+			t.Fatalf("invalid slice thickness %f at z=%f", dz, zAxis[i])
+		}
+		for j := range z {
+			if math.Abs(zAxis[i-1]-z[j]) < REFINEMENT_RANGE*testSigma[j] && dz > testSigma[j]/SLICES_PER_SIGMA+1e-12 {
+				t.Errorf("slice at z=%f is not refined near interface %d: %f", zAxis[i-1], j, dz)
+			}
+		}
+	}
+
+	if zAxis[0] > -REFINEMENT_RANGE*testSigma[0] || zAxis[len(zAxis)-1] < z[len(z)-1]+REFINEMENT_RANGE*testSigma[len(z)-1] {
+		t.Errorf("z axis does not cover all interfaces: %f to %f", zAxis[0], zAxis[len(zAxis)-1])
+	}
+}
+
+// a thick film is undersampled by the fixed z axis but not by the adaptive one
+func TestAdaptiveProfileThickFilm(t *testing.T) {
+	eden := []float64{0.0, 0.35, 0.46, 0.334}
+	d := []float64{400, 10}
+	sigma := []float64{1, 1.5, 2}
+	qz := helperSyntheticQz(t)
+
+	reflectivity := func(edenPoints []float64, zAxis []float64) []float64 {
+		sld := make([]complex128, len(edenPoints))
+		for i, e := range edenPoints {
+			sld[i] = complex(e*ELECTRON_RADIUS, 0)
+		}
+		stack, err := NewProfileStack(zAxis, sld)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return CalculateParrattReflectivity(qz, stack)
+	}
+	profileReflectivity := func(zAxis []float64) []float64 {
+		points := getErfProfileOnAxis(zAxis, eden, d, sigma)
+		y := make([]float64, len(points))
+		for i, p := range points {
+			y[i] = p.Y
+		}
+		return reflectivity(y, zAxis)
+	}
+
+	// reference with a very fine equidistant axis
+	fine := make([]float64, 0)
+	for z := -20.0; z < 440; z += 0.05 {
+		fine = append(fine, z)
+	}
+	reference := profileReflectivity(fine)
+
+	adaptiveAxis, err := GetAdaptiveZAxis(d, sigma, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maxError := func(refl []float64) float64 {
+		e := 0.0
+		for i := range refl {
+			e = max(e, math.Abs(refl[i]-reference[i])/reference[i])
+		}
+		return e
+	}
+	fixedError := maxError(profileReflectivity(GetZAxis(d, ZNUMBER)))
+	adaptiveError := maxError(profileReflectivity(adaptiveAxis))
+
+	if adaptiveError > 0.01 || adaptiveError >= fixedError {
+		t.Errorf("adaptive z axis is not more accurate than the fixed one: %g vs %g", adaptiveError, fixedError)
+	}
+}
+
+// an equidistant profile stack is the same as a sliced stack
+func TestProfileStackEquidistant(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zAxis := make([]float64, len(edenPoints))
+	for i, p := range edenPoints {
+		zAxis[i] = p.X
+	}
+	stack, err := NewProfileStack(zAxis, sld)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qz := GetDefaultQZAxis(500)
+	refl := CalculateParrattReflectivity(qz, stack)
+	sliced := CalculateComplexReflectivity(qz, edenPoints[1].X-edenPoints[0].X, sld)
+	for i := range qz {
+		if math.Abs(refl[i]-sliced[i]) > 1e-9*sliced[i] {
+			t.Errorf("reflectivity differs at q=%f: %g vs %g", qz[i], refl[i], sliced[i])
+		}
+	}
+}
+
+func TestAdaptiveZAxisInvalid(t *testing.T) {
+	if _, err := GetAdaptiveZAxis(testD, testSigma, 0); err == nil {
+		t.Error("expected error for a maximum slice of 0")
+	}
+	if _, err := GetAdaptiveZAxis(testD, testSigma[:1], 1); err == nil {
+		t.Error("expected error for wrong number of roughnesses")
+	}
+	if _, err := GetAdaptiveZAxis([]float64{math.Inf(1)}, []float64{1, 1}, 1); err == nil {
+		t.Error("expected error for an infinite thickness")
+	}
+}
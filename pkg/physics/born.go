@@ -0,0 +1,82 @@
+package physics
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// KinematicEngine calculates the reflectivity in the born approximation (see CalculateBornReflectivity)
+// it is not one of the selectable engines, the "born" function shows it next to the dynamical intensity
+type KinematicEngine struct{}
+
+func (KinematicEngine) Name() string {
+	return "Born"
+}
+
+func (KinematicEngine) Reflectivity(qzaxis []float64, stack *Stack) []float64 {
+	return CalculateBornReflectivity(qzaxis, stack)
+}
+
+// calculates the reflectivity of a single sharp interface between the ambient medium and the substrate
+// the reflectivity is symmetric in q
+func CalculateFresnelReflectivity(qzaxis []float64, sldAmbient, sldSubstrate complex128) []float64 {
+	refl := make([]float64, len(qzaxis))
+	for i, q := range qzaxis {
+		k0 := complex(math.Abs(q)/2, 0)
+		k1 := waveVector(math.Abs(q)/2, sldSubstrate-sldAmbient)
+		if k0+k1 == 0 {
+			continue // no contrast at q = 0
+		}
+		refl[i] = math.Pow(cmplx.Abs((k0-k1)/(k0+k1)), 2)
+	}
+	return refl
+}
+
+// calculates the kinematic reflectivity of a stack (born approximation)
+//
+// the fourier transform of the sld gradient dρ/dz is multiplied with the fresnel reflectivity of the substrate:
+// R(q) = R_F(q) |1/Δρ Σ_j Δρ_j exp(i q z_j) exp(-q² σ_j² / 2)|²
+// with the sld steps Δρ_j at the interfaces z_j, their roughness σ_j (slab stacks) and the total step Δρ,
+// without contrast between ambient medium and substrate R(q) = 16π²/q⁴ |Σ_j ...|² is used (limited to 1)
+//
+// multiple scattering is neglected, so the result is only valid well above the critical edge
+func CalculateBornReflectivity(qzaxis []float64, stack *Stack) []float64 {
+	nmedia := len(stack.SLD)
+
+	refl := make([]float64, len(qzaxis))
+	if nmedia < 2 {
+		return refl
+	}
+
+	// position of every interface, the first one is at z = 0
+	z := make([]float64, nmedia-1)
+	for i := 1; i < len(z); i++ {
+		z[i] = z[i-1] + stack.Thickness[i-1]
+	}
+
+	total := stack.SLD[nmedia-1] - stack.SLD[0]
+	fresnel := CalculateFresnelReflectivity(qzaxis, stack.SLD[0], stack.SLD[nmedia-1])
+
+	for iq, q := range qzaxis {
+		var transform complex128
+		for i := range z {
+			step := stack.SLD[i+1] - stack.SLD[i]
+			if step == 0 {
+				continue
+			}
+			damping := 1.0
+			if stack.Roughness != nil {
+				damping = math.Exp(-q * q * stack.Roughness[i] * stack.Roughness[i] / 2)
+			}
+			transform += step * cmplx.Exp(complex(0, q*z[i])) * complex(damping, 0)
+		}
+
+		if total != 0 {
+			refl[iq] = fresnel[iq] * math.Pow(cmplx.Abs(transform/total), 2)
+		} else if transform != 0 {
+			refl[iq] = math.Min(16*math.Pi*math.Pi/math.Pow(q, 4)*math.Pow(cmplx.Abs(transform), 2), 1)
+		}
+	}
+
+	return refl
+}
@@ -0,0 +1,47 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// a single rough interface gives the fresnel reflectivity damped by exp(-q²σ²)
+func TestBornReflectivityInterface(t *testing.T) {
+	qz := []float64{0.01, 0.05, 0.1, 0.2, 0.4}
+	sigma := 3.0
+
+	stack, err := NewSlabStack([]float64{0, 0.7}, []float64{}, []float64{sigma}, nil, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := CalculateBornReflectivity(qz, stack)
+	fresnel := CalculateFresnelReflectivity(qz, stack.SLD[0], stack.SLD[1])
+
+	for i, q := range qz {
+		expected := fresnel[i] * math.Exp(-q*q*sigma*sigma)
+		if math.Abs(born[i]-expected) > 1e-12*expected {
+			t.Errorf("expected %g at q=%f got %g", expected, q, born[i])
+		}
+	}
+	if math.Abs(fresnel[0]-1) > 1e-12 {
+		t.Errorf("expected total reflection below the critical edge, got %g", fresnel[0])
+	}
+}
+
+// well above the critical edge and away from the minima the born approximation approaches the dynamical reflectivity
+func TestBornReflectivityHighQ(t *testing.T) {
+	qz := []float64{0.175, 0.2, 0.225, 0.375, 0.4, 0.425}
+
+	stack, err := NewSlabStack([]float64{0, 0.35, 0.7}, []float64{30}, []float64{0, 0}, nil, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := CalculateBornReflectivity(qz, stack)
+	parratt := CalculateParrattReflectivity(qz, stack)
+
+	for i, q := range qz {
+		if math.Abs(born[i]-parratt[i]) > 0.03*parratt[i] {
+			t.Errorf("born %g and parratt %g differ at q=%f", born[i], parratt[i], q)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+)
+
+// CostFunction calculates the contribution of a data point to the figure of merit minimised by the fit
+type CostFunction interface {
+	// name shown in the gui and stored in config files
+	Name() string
+	// returns the cost of a data point for the calculated intensity y
+	Cost(point *function.Point, y float64) float64
+}
+
+// Q2ChiSquaredCost weights the squared normalised residual with q^2
+type Q2ChiSquaredCost struct{}
+
+func (Q2ChiSquaredCost) Name() string {
+	return "q² χ²"
+}
+
+func (Q2ChiSquaredCost) Cost(point *function.Point, y float64) float64 {
+	return point.X * point.X * normalisedResidual(point, y)
+}
+
+// ChiSquaredCost is the squared residual normalised by the error of the data point
+type ChiSquaredCost struct{}
+
+func (ChiSquaredCost) Name() string {
+	return "χ²"
+}
+
+func (ChiSquaredCost) Cost(point *function.Point, y float64) float64 {
+	return normalisedResidual(point, y)
+}
+
+// Q4ChiSquaredCost weights the squared normalised residual with q^4: q^4 * ((R_model - R_data) / error)^2
+// emphasises the high q region like the R·q^4 graphs. It is not the χ² of the R·q^4 curves,
+// scaling model, data and error by q^4 would cancel out and give the plain χ²
+type Q4ChiSquaredCost struct{}
+
+func (Q4ChiSquaredCost) Name() string {
+	return "q⁴ χ²"
+}
+
+func (Q4ChiSquaredCost) Cost(point *function.Point, y float64) float64 {
+	q2 := point.X * point.X
+	return q2 * q2 * normalisedResidual(point, y)
+}
+
+// LogResidualCost is the squared difference of the logarithms, every decade of the reflectivity counts the same
+// points with a non positive intensity are ignored
+type LogResidualCost struct{}
+
+func (LogResidualCost) Name() string {
+	return "Log residual"
+}
+
+func (LogResidualCost) Cost(point *function.Point, y float64) float64 {
+	if point.Y <= 0 || y <= 0 {
+		return 0
+	}
+	return math.Pow(math.Log(y)-math.Log(point.Y), 2)
+}
+
+// PoissonDevianceCost is the deviance of poisson distributed counts
+// the counts are estimated from the relative error of the data point (N = (Y/Error)^2),
+// points without error are treated as counts
+type PoissonDevianceCost struct{}
+
+func (PoissonDevianceCost) Name() string {
+	return "Poisson deviance"
+}
+
+func (PoissonDevianceCost) Cost(point *function.Point, y float64) float64 {
+	counts, model := point.Y, y
+	if point.Error > 0 && point.Y > 0 {
+		factor := point.Y / (point.Error * point.Error)
+		counts, model = counts*factor, model*factor
+	}
+	model = math.Max(model, math.SmallestNonzeroFloat64)
+
+	if counts <= 0 {
+		return 2 * model
+	}
+	return 2 * (model - counts + counts*math.Log(counts/model))
+}
+
+// UnweightedCost is the squared residual for data without errors
+type UnweightedCost struct{}
+
+func (UnweightedCost) Name() string {
+	return "Unweighted"
+}
+
+func (UnweightedCost) Cost(point *function.Point, y float64) float64 {
+	return math.Pow(y-point.Y, 2)
+}
+
+// squared residual normalised by the error, points without error are not normalised
+func normalisedResidual(point *function.Point, y float64) float64 {
+	if point.Error <= 0 {
+		return math.Pow(y-point.Y, 2)
+	}
+	return math.Pow((y-point.Y)/point.Error, 2)
+}
+
+// available cost functions, the first one is the default
+var costFunctions = []CostFunction{Q2ChiSquaredCost{}, ChiSquaredCost{}, Q4ChiSquaredCost{}, LogResidualCost{}, PoissonDevianceCost{}, UnweightedCost{}}
+
+// DefaultCostFunction returns the cost function used if none is selected
+func DefaultCostFunction() CostFunction {
+	return costFunctions[0]
+}
+
+// CostFunctionNames returns the names of all available cost functions
+func CostFunctionNames() []string {
+	names := make([]string, len(costFunctions))
+	for i, cost := range costFunctions {
+		names[i] = cost.Name()
+	}
+	return names
+}
+
+// GetCostFunction returns the cost function with the given name
+func GetCostFunction(name string) (CostFunction, error) {
+	for _, cost := range costFunctions {
+		if cost.Name() == name {
+			return cost, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cost function '%s'", name)
+}
@@ -0,0 +1,67 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"testing"
+)
+
+// a perfect model has no cost and every cost function is found by its name
+func TestCostFunctions(t *testing.T) {
+	point := &function.Point{X: 0.1, Y: 1e-4, Error: 1e-5}
+
+	for _, name := range CostFunctionNames() {
+		cost, err := GetCostFunction(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c := cost.Cost(point, point.Y); math.Abs(c) > 1e-12 {
+			t.Errorf("%s: expected no cost for a perfect model got %g", name, c)
+		}
+		if c := cost.Cost(point, 2*point.Y); c <= 0 {
+			t.Errorf("%s: expected a positive cost for a wrong model got %g", name, c)
+		}
+	}
+
+	if _, err := GetCostFunction("unknown"); err == nil {
+		t.Error("expected an error for an unknown cost function")
+	}
+}
+
+// the default cost function keeps the q^2 weighted penalty
+func TestDefaultCostFunction(t *testing.T) {
+	point := &function.Point{X: 0.2, Y: 2.0, Error: 0.5}
+
+	expected := math.Pow(point.X, 2) * math.Pow((3.0-point.Y)/point.Error, 2)
+	if c := DefaultCostFunction().Cost(point, 3.0); math.Abs(c-expected) > 1e-12 {
+		t.Errorf("expected %g got %g", expected, c)
+	}
+}
+
+// data points between the q values of the intensity are compared to the interpolated intensity
+func TestCalculateCostInterpolated(t *testing.T) {
+	intensity := function.Points{
+		{X: 0.1, Y: 1e-2},
+		{X: 0.2, Y: 1e-4},
+		{X: 0.3, Y: 1e-6},
+	}
+
+	// exactly on the logarithmic interpolation of the intensity
+	data := []function.Points{
+		{{X: 0.15, Y: 1e-3, Error: 1e-4}},
+		{{X: 0.2, Y: 1e-4, Error: 1e-5}, {X: 0.25, Y: 1e-5, Error: 1e-6}},
+	}
+	cost, err := CalculateCost(ChiSquaredCost{}, data, intensity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost > 1e-12 {
+		t.Errorf("expected no cost got %g", cost)
+	}
+
+	outside := []function.Points{{{X: 0.35, Y: 1e-7, Error: 1e-8}}}
+	if _, err := CalculateCost(ChiSquaredCost{}, outside, intensity); err == nil {
+		t.Error("expected an error for a point outside the intensity")
+	}
+}
@@ -29,37 +29,95 @@ func GetEdensities(eden []float64, d []float64, sigma []float64) (function.Point
 		return nil, fmt.Errorf("missmatch in parameter dimensionality roughness %d/thickness %d", len(sigma), len(d))
 	}
 
-	//calculate distances
-	var z = make([]float64, step_n)
-	z[0] = 0.0
-	for i := 1; i < step_n; i++ {
-		z[i] = z[i-1] + d[i-1]
+	return getErfProfile(eden, d, sigma), nil
+}
+
+// GetAbsorptions returns the absorption profile on the same z axis as GetEdensities
+// - absorption is an array with all the absorption values {absorption_a,absorption_1,...,absorption_n,absorption_b}
+// - d and sigma are the same as for GetEdensities
+//
+// absorption values use the same units as the eden values, the imaginary part of the sld is -absorption*ELECTRON_RADIUS
+func GetAbsorptions(absorption []float64, d []float64, sigma []float64) (function.Points, error) {
+	step_n := len(d) + 1
+
+	if len(absorption) != step_n+1 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality absorptions %d/thickness %d", len(absorption), len(d))
+	}
+	if len(sigma) != step_n {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality roughness %d/thickness %d", len(sigma), len(d))
 	}
 
-	edensities := make(function.Points, ZNUMBER)
-	zaxis := GetZAxis(d, ZNUMBER)
+	return getErfProfile(absorption, d, sigma), nil
+}
+
+// getErfProfile calculates a profile of error function steps between the layer values on the default z axis
+func getErfProfile(values []float64, d []float64, sigma []float64) function.Points {
+	return getErfProfileOnAxis(GetZAxis(d, microsliceNumber(d, sigma)), values, d, sigma)
+}
+
+// microsliceNumber returns the number of slices of the default z axis
+// thick stacks (e.g. superlattices) get more than ZNUMBER slices, so that no slice is thicker than
+// the smallest roughness or MAX_MICROSLICE
+func microsliceNumber(d []float64, sigma []float64) int {
+	step := MAX_MICROSLICE
+	for _, s := range sigma {
+		if s = math.Abs(s); s > 0 {
+			step = min(step, max(s, MIN_SLICE))
+		}
+	}
 
-	for i := 0; i < ZNUMBER; i++ {
-		z_i := zaxis[i]
+	z0, z1 := zRange(d)
+
+	return min(max(ZNUMBER, int(math.Ceil((z1-z0)/step))), MAX_SLICES)
+}
 
-		//calculate cumulative edensity at a specific z_i
+// getErfProfileOnAxis calculates a profile of error function steps between the layer values at the given z values
+func getErfProfileOnAxis(zaxis []float64, values []float64, d []float64, sigma []float64) function.Points {
+	step_n := len(d) + 1
+
+	//calculate distances
+	z := interfacePositions(d)
+
+	profile := make(function.Points, len(zaxis))
+
+	for i, z_i := range zaxis {
+		//calculate cumulative value at a specific z_i
 		y := 0.0
 		for step := 0; step < step_n; step++ {
-			y += (eden[step+1] - eden[step]) * 0.5 * (1.0 + math.Erf((z_i-z[step])/(math.Sqrt2*math.Abs(sigma[step]))))
+			y += (values[step+1] - values[step]) * erfStep(z_i-z[step], sigma[step])
 		}
 
 		//create points for drawing
-		edensities[i] = &function.Point{
+		profile[i] = &function.Point{
 			X:     z_i,
 			Y:     y,
 			Error: 0,
 		}
 	}
 
-	return edensities, nil
+	return profile
 }
 
-func GetZAxis(d []float64, zNumber int) []float64 {
+// erfStep returns the error function step of an interface with roughness sigma at the distance dz
+// sharp interfaces (sigma=0) give 0.5 directly at the interface
+func erfStep(dz float64, sigma float64) float64 {
+	if sigma == 0 && dz == 0 {
+		return 0.5
+	}
+	return 0.5 * (1.0 + math.Erf(dz/(math.Sqrt2*math.Abs(sigma))))
+}
+
+// interfacePositions returns the z position of every interface, the first one is at z=0
+func interfacePositions(d []float64) []float64 {
+	z := make([]float64, len(d)+1)
+	for i := 1; i < len(z); i++ {
+		z[i] = z[i-1] + d[i-1]
+	}
+	return z
+}
+
+// zRange returns the first and last z value of the default z axis
+func zRange(d []float64) (float64, float64) {
 	z0 := -20.0
 	var z1 = 30.0
 	if len(d) > 3 {
@@ -68,6 +126,11 @@ func GetZAxis(d []float64, zNumber int) []float64 {
 	for _, f := range d {
 		z1 += f
 	}
+	return z0, z1
+}
+
+func GetZAxis(d []float64, zNumber int) []float64 {
+	z0, z1 := zRange(d)
 
 	zStep := (z1 - z0) / float64(zNumber)
 	zAxis := make([]float64, zNumber)
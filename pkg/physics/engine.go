@@ -0,0 +1,59 @@
+package physics
+
+import "fmt"
+
+// ReflectivityEngine calculates the reflectivity of a stack (microsliced profile or slab model)
+type ReflectivityEngine interface {
+	// name shown in the gui and stored in config files
+	Name() string
+	Reflectivity(qzaxis []float64, stack *Stack) []float64
+}
+
+// ParrattEngine uses the Parratt recursion (see CalculateParrattReflectivity)
+type ParrattEngine struct{}
+
+func (ParrattEngine) Name() string {
+	return "Parratt"
+}
+
+func (ParrattEngine) Reflectivity(qzaxis []float64, stack *Stack) []float64 {
+	return CalculateParrattReflectivity(qzaxis, stack)
+}
+
+// AbelesEngine uses the Abeles matrices (see CalculateStackAbelesReflectivity)
+type AbelesEngine struct{}
+
+func (AbelesEngine) Name() string {
+	return "Abeles"
+}
+
+func (AbelesEngine) Reflectivity(qzaxis []float64, stack *Stack) []float64 {
+	return CalculateStackAbelesReflectivity(qzaxis, stack)
+}
+
+// available reflectivity engines, the first one is the default
+var engines = []ReflectivityEngine{ParrattEngine{}, AbelesEngine{}}
+
+// DefaultEngine returns the engine used if none is selected
+func DefaultEngine() ReflectivityEngine {
+	return engines[0]
+}
+
+// EngineNames returns the names of all available reflectivity engines
+func EngineNames() []string {
+	names := make([]string, len(engines))
+	for i, engine := range engines {
+		names[i] = engine.Name()
+	}
+	return names
+}
+
+// GetEngine returns the reflectivity engine with the given name
+func GetEngine(name string) (ReflectivityEngine, error) {
+	for _, engine := range engines {
+		if engine.Name() == name {
+			return engine, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown reflectivity engine '%s'", name)
+}
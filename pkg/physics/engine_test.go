@@ -0,0 +1,105 @@
+package physics
+
+import (
+	"math"
+	"os"
+	"path"
+	"physicsGUI/pkg/data"
+	"testing"
+)
+
+// q values of the synthetic dataset
+func helperSyntheticQz(t *testing.T) []float64 {
+	bytes, err := os.ReadFile(path.Join("..", "..", "testdata", "syntheticdataset.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	points, err := data.Parse(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qz := make([]float64, len(points))
+	for i, p := range points {
+		qz[i] = p.X
+	}
+	return qz
+}
+
+// checks that both engines agree within a relative tolerance
+func helperCompareEngines(t *testing.T, qz []float64, deltaz float64, sld []complex128) {
+	parratt := ParrattEngine{}.Reflectivity(qz, NewSlicedStack(deltaz, sld))
+	abeles := AbelesEngine{}.Reflectivity(qz, NewSlicedStack(deltaz, sld))
+
+	for i := range qz {
+		if math.IsNaN(abeles[i]) || math.Abs(parratt[i]-abeles[i]) > 1e-9*parratt[i] {
+			t.Errorf("engines differ at q=%f: parratt %g vs abeles %g", qz[i], parratt[i], abeles[i])
+		}
+	}
+}
+
+// Parratt and Abeles need to give the same reflectivity for the profile of the synthetic dataset
+func TestEnginesAgreeOnSyntheticDataset(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helperCompareEngines(t, helperSyntheticQz(t), edenPoints[1].X-edenPoints[0].X, sld)
+}
+
+// the engines also need to agree for absorbing media and below the critical edge
+func TestEnginesAgreeWithAbsorption(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	absorptionPoints, err := GetAbsorptions([]float64{0, 0.005, 0.001, 0.01}, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, absorptionPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helperCompareEngines(t, GetDefaultQZAxis(500), edenPoints[1].X-edenPoints[0].X, sld)
+}
+
+// a single interface needs to give the fresnel reflectivity
+func TestAbelesFresnel(t *testing.T) {
+	sld := []complex128{0, complex(0.334*ELECTRON_RADIUS, 0)}
+	qc := 4 * math.Sqrt(math.Pi*real(sld[1]))
+
+	for _, q := range []float64{0.5 * qc, 2 * qc, 10 * qc} {
+		k0 := complex(q/2, 0)
+		k1 := waveVector(q/2, sld[1])
+		r := (k0 - k1) / (k0 + k1)
+		expected := real(r)*real(r) + imag(r)*imag(r)
+
+		refl := CalculateAbelesReflectivity([]float64{q}, 1, sld)
+		if math.Abs(refl[0]-expected) > 1e-12 {
+			t.Errorf("fresnel reflectivity at q=%f: got %g, expected %g", q, refl[0], expected)
+		}
+	}
+}
+
+func TestGetEngine(t *testing.T) {
+	for _, name := range EngineNames() {
+		engine, err := GetEngine(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if engine.Name() != name {
+			t.Errorf("expected engine %s, got %s", name, engine.Name())
+		}
+	}
+
+	if _, err := GetEngine("unknown"); err == nil {
+		t.Error("expected error for unknown engine")
+	}
+}
@@ -0,0 +1,60 @@
+package physics
+
+import (
+	"math"
+)
+
+// BeamProfile is the intensity profile of the incoming beam perpendicular to its direction
+type BeamProfile string
+
+const (
+	// constant intensity over the beam width
+	UNIFORM_BEAM = BeamProfile("Uniform")
+	// gaussian profile, the beam width is its full width at half maximum
+	GAUSSIAN_BEAM = BeamProfile("Gaussian")
+)
+
+// BeamProfileNames returns the names of all beam profiles
+func BeamProfileNames() []string {
+	return []string{string(UNIFORM_BEAM), string(GAUSSIAN_BEAM)}
+}
+
+// Footprint describes the illumination of the sample, at low angles the beam is wider than the projected sample
+// and only a fraction of it is reflected
+type Footprint struct {
+	// width of the beam perpendicular to its direction in mm (full width for uniform, fwhm for gaussian beams)
+	BeamWidth float64
+	// length of the sample along the beam in mm
+	SampleLength float64
+	// wavelength in angstrom, converts q into the angle of incidence
+	Wavelength float64
+	Profile    BeamProfile
+}
+
+// Angle returns the footprint angle (radian) below which the beam overfills the sample
+func (f *Footprint) Angle() float64 {
+	if f == nil || !(f.BeamWidth > 0) || !(f.SampleLength > 0) {
+		return 0
+	}
+	return math.Asin(math.Min(f.BeamWidth/f.SampleLength, 1))
+}
+
+// Factor returns the fraction of the beam hitting the sample at q
+// a nil footprint or non positive beam width, sample length or wavelength disable the correction (factor 1)
+func (f *Footprint) Factor(q float64) float64 {
+	if f == nil || !(f.BeamWidth > 0) || !(f.SampleLength > 0) || !(f.Wavelength > 0) {
+		return 1
+	}
+
+	// width of the sample seen by the beam
+	sinTheta := math.Min(math.Abs(q)*f.Wavelength/(4*math.Pi), 1)
+	projected := f.SampleLength * sinTheta
+
+	switch f.Profile {
+	case GAUSSIAN_BEAM:
+		sigma := f.BeamWidth / (2 * math.Sqrt(2*math.Ln2))
+		return math.Erf(projected / (2 * math.Sqrt2 * sigma))
+	default:
+		return math.Min(projected/f.BeamWidth, 1)
+	}
+}
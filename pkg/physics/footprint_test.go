@@ -0,0 +1,34 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// the uniform beam is cut linearly below the footprint angle, the gaussian beam smoothly
+func TestFootprintFactor(t *testing.T) {
+	footprint := &Footprint{BeamWidth: 0.1, SampleLength: 10, Wavelength: 1.54, Profile: UNIFORM_BEAM}
+
+	// q of the footprint angle
+	qf := 4 * math.Pi * math.Sin(footprint.Angle()) / footprint.Wavelength
+	if math.Abs(footprint.Factor(qf)-1) > 1e-12 || footprint.Factor(2*qf) != 1 {
+		t.Errorf("expected full illumination above the footprint angle, got %f", footprint.Factor(qf))
+	}
+	if math.Abs(footprint.Factor(qf/2)-0.5) > 1e-6 || math.Abs(footprint.Factor(-qf/2)-0.5) > 1e-6 {
+		t.Errorf("expected half illumination at half the footprint angle, got %f", footprint.Factor(qf/2))
+	}
+
+	// the fwhm of the gaussian beam matches the sample at the footprint angle
+	footprint.Profile = GAUSSIAN_BEAM
+	if expected := math.Erf(math.Sqrt(math.Ln2)); math.Abs(footprint.Factor(qf)-expected) > 1e-12 {
+		t.Errorf("expected %f at the footprint angle got %f", expected, footprint.Factor(qf))
+	}
+	if footprint.Factor(10*qf) < 0.999999 || footprint.Factor(0) != 0 {
+		t.Errorf("gaussian footprint not normalised: %f %f", footprint.Factor(10*qf), footprint.Factor(0))
+	}
+
+	var disabled *Footprint
+	if disabled.Factor(qf/2) != 1 || (&Footprint{}).Factor(qf/2) != 1 {
+		t.Error("expected no correction without footprint")
+	}
+}
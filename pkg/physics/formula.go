@@ -0,0 +1,310 @@
+package physics
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"unicode"
+)
+
+// parameter group of the mass densities of eden parameters defined by a chemical formula
+const DENSITY_GROUP = "density"
+
+// avogadro constant in 1/mol
+const AVOGADRO = 6.02214076e23
+
+// Radiation is the probe the scattering length density of a material is calculated for
+type Radiation string
+
+const (
+	// electron density (electrons/Å³), used as eden directly
+	XRAY_RADIATION = Radiation("X-ray")
+	// coherent neutron sld, divided by ELECTRON_RADIUS to be used as eden
+	NEUTRON_RADIATION = Radiation("Neutron")
+)
+
+// RadiationNames returns the names of all radiations
+func RadiationNames() []string {
+	return []string{string(XRAY_RADIATION), string(NEUTRON_RADIATION)}
+}
+
+// Element holds the scattering properties of an element (natural isotope mixture)
+type Element struct {
+	// number of electrons, the x-ray form factor in forward direction without anomalous dispersion
+	Z int
+	// atomic mass in g/mol
+	Mass float64
+	// bound coherent neutron scattering length in fm
+	B float64
+	// x-ray atomic scattering factors in forward direction at Cu Kα (8.048 keV, 1.5406 Å) in electrons,
+	// f1 = Z + f′ (anomalous dispersion) and f2 = f″ (absorption)
+	F1, F2 float64
+	// neutron absorption cross section in barn at NEUTRON_ABSORPTION_WAVELENGTH (2200 m/s)
+	SigmaAbs float64
+}
+
+// wavelength in Å the neutron absorption cross sections are given for
+const NEUTRON_ABSORPTION_WAVELENGTH = 1.798
+
+// elements by their symbol, neutron scattering lengths and absorption cross sections from Sears (Neutron News 3, 1992),
+// x-ray scattering factors from the Cu Kα dispersion corrections of the International Tables for Crystallography Vol. C
+// (values of the heavy elements rounded)
+var elements = map[string]Element{
+	"H":  {1, 1.008, -3.739, 1, 0, 0.3326},
+	"D":  {1, 2.014, 6.671, 1, 0, 0.000519},
+	"He": {2, 4.0026, 3.26, 2, 0, 0.00747},
+	"Li": {3, 6.94, -1.90, 3.001, 0, 70.5},
+	"Be": {4, 9.0122, 7.79, 4.003, 0.001, 0.0076},
+	"B":  {5, 10.81, 5.30, 5.008, 0.004, 767},
+	"C":  {6, 12.011, 6.646, 6.017, 0.009, 0.0035},
+	"N":  {7, 14.007, 9.36, 7.029, 0.018, 1.9},
+	"O":  {8, 15.999, 5.803, 8.047, 0.032, 0.00019},
+	"F":  {9, 18.998, 5.654, 9.069, 0.053, 0.0096},
+	"Ne": {10, 20.180, 4.566, 10.097, 0.083, 0.039},
+	"Na": {11, 22.990, 3.63, 11.129, 0.124, 0.53},
+	"Mg": {12, 24.305, 5.375, 12.165, 0.177, 0.063},
+	"Al": {13, 26.982, 3.449, 13.204, 0.246, 0.231},
+	"Si": {14, 28.085, 4.1491, 14.244, 0.33, 0.171},
+	"P":  {15, 30.974, 5.13, 15.283, 0.434, 0.172},
+	"S":  {16, 32.06, 2.847, 16.319, 0.557, 0.53},
+	"Cl": {17, 35.45, 9.577, 17.348, 0.702, 33.5},
+	"Ar": {18, 39.948, 1.909, 18.366, 0.872, 0.675},
+	"K":  {19, 39.098, 3.67, 19.365, 1.066, 2.1},
+	"Ca": {20, 40.078, 4.70, 20.341, 1.286, 0.43},
+	"Sc": {21, 44.956, 12.29, 21.285, 1.533, 27.5},
+	"Ti": {22, 47.867, -3.438, 22.189, 1.807, 6.09},
+	"V":  {23, 50.942, -0.3824, 23.035, 2.11, 5.08},
+	"Cr": {24, 51.996, 3.635, 23.802, 2.443, 3.05},
+	"Mn": {25, 54.938, -3.73, 24.432, 2.808, 13.3},
+	"Fe": {26, 55.845, 9.45, 24.821, 3.204, 2.56},
+	"Co": {27, 58.933, 2.49, 24.536, 3.608, 37.18},
+	"Ni": {28, 58.693, 10.3, 25.044, 0.509, 4.49},
+	"Cu": {29, 63.546, 7.718, 26.981, 0.589, 3.78},
+	"Zn": {30, 65.38, 5.680, 28.388, 0.678, 1.11},
+	"Ga": {31, 69.723, 7.288, 29.646, 0.777, 2.75},
+	"Ge": {32, 72.630, 8.185, 30.837, 0.886, 2.2},
+	"As": {33, 74.922, 6.58, 31.989, 1.006, 4.5},
+	"Se": {34, 78.971, 7.970, 33.121, 1.139, 11.7},
+	"Br": {35, 79.904, 6.795, 34.233, 1.283, 6.9},
+	"Kr": {36, 83.798, 7.81, 35.335, 1.439, 25},
+	"Rb": {37, 85.468, 7.09, 36.426, 1.608, 0.38},
+	"Sr": {38, 87.62, 7.02, 37.535, 1.82, 1.28},
+	"Y":  {39, 88.906, 7.75, 38.614, 2.025, 1.28},
+	"Zr": {40, 91.224, 7.16, 39.686, 2.245, 0.185},
+	"Nb": {41, 92.906, 7.054, 40.752, 2.482, 1.15},
+	"Mo": {42, 95.95, 6.715, 41.809, 2.735, 2.48},
+	"Ru": {44, 101.07, 7.03, 43.895, 3.296, 2.56},
+	"Rh": {45, 102.91, 5.88, 44.923, 3.605, 144.8},
+	"Pd": {46, 106.42, 5.91, 45.941, 3.934, 6.9},
+	"Ag": {47, 107.87, 5.922, 46.94, 4.282, 63.3},
+	"Cd": {48, 112.41, 4.87, 47.921, 4.653, 2520},
+	"In": {49, 114.82, 4.065, 48.874, 5.045, 193.8},
+	"Sn": {50, 118.71, 6.225, 49.806, 5.459, 0.626},
+	"Sb": {51, 121.76, 5.57, 50.713, 5.894, 4.91},
+	"Te": {52, 127.60, 5.80, 51.582, 6.352, 4.7},
+	"I":  {53, 126.90, 5.28, 52.421, 6.835, 6.15},
+	"Xe": {54, 131.29, 4.92, 53.217, 7.348, 23.9},
+	"Cs": {55, 132.91, 5.42, 53.978, 7.904, 29},
+	"Ba": {56, 137.33, 5.07, 54.666, 8.46, 1.1},
+	"La": {57, 138.91, 8.24, 55.284, 9.036, 8.97},
+	"Ce": {58, 140.12, 4.84, 55.83, 9.651, 0.63},
+	"Nd": {60, 144.24, 7.69, 56.569, 11.353, 50.5},
+	"Gd": {64, 157.25, 6.5, 54.758, 11.072, 49700},
+	"Dy": {66, 162.50, 16.9, 55.577, 9.934, 994},
+	"Hf": {72, 178.49, 7.7, 65.8, 5, 104.1},
+	"Ta": {73, 180.95, 6.91, 67.1, 5.3, 20.6},
+	"W":  {74, 183.84, 4.86, 68.5, 5.6, 18.3},
+	"Ir": {77, 192.22, 10.6, 72.1, 6.6, 425},
+	"Pt": {78, 195.08, 9.60, 73.4, 7, 10.3},
+	"Au": {79, 196.97, 7.63, 74.6, 7.4, 98.65},
+	"Hg": {80, 200.59, 12.692, 75.7, 7.7, 372.3},
+	"Pb": {82, 207.2, 9.405, 77.925, 8.506, 0.171},
+	"Bi": {83, 208.98, 8.532, 78.892, 8.93, 0.0338},
+	"U":  {92, 238.03, 8.417, 87.8, 13.4, 7.57},
+}
+
+// ElementSymbols returns the symbols of all elements ordered by their number of electrons
+func ElementSymbols() []string {
+	symbols := slices.Collect(maps.Keys(elements))
+	slices.SortFunc(symbols, func(a, b string) int {
+		return cmp.Or(cmp.Compare(elements[a].Z, elements[b].Z), cmp.Compare(elements[a].Mass, elements[b].Mass))
+	})
+	return symbols
+}
+
+// GetElement returns the element with the given symbol
+func GetElement(symbol string) (Element, error) {
+	element, ok := elements[symbol]
+	if !ok {
+		return Element{}, fmt.Errorf("unknown element '%s'", symbol)
+	}
+	return element, nil
+}
+
+// ParseFormula returns the number of atoms per element of a chemical formula
+// groups in parentheses or brackets can be multiplied, counts may be fractional (e.g. "Ca(OH)2", "Si0.9Ge0.1")
+func ParseFormula(formula string) (map[string]float64, error) {
+	runes := []rune(formula)
+	stack := []map[string]float64{make(map[string]float64)}
+	closing := make([]rune, 0)
+
+	// reads the count following an element or group, 1 if there is none
+	count := func(i int) (float64, int, error) {
+		start := i
+		for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+			i++
+		}
+		if start == i {
+			return 1, i, nil
+		}
+		n, err := strconv.ParseFloat(string(runes[start:i]), 64)
+		if err != nil || n <= 0 {
+			return 0, i, fmt.Errorf("formula '%s': invalid count '%s'", formula, string(runes[start:i]))
+		}
+		return n, i, nil
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == '[':
+			stack = append(stack, make(map[string]float64))
+			closing = append(closing, map[rune]rune{'(': ')', '[': ']'}[r])
+			i++
+		case r == ')' || r == ']':
+			if len(closing) == 0 || closing[len(closing)-1] != r {
+				return nil, fmt.Errorf("formula '%s': unexpected '%c'", formula, r)
+			}
+			n, next, err := count(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			group := stack[len(stack)-1]
+			stack, closing = stack[:len(stack)-1], closing[:len(closing)-1]
+			for symbol, atoms := range group {
+				stack[len(stack)-1][symbol] += atoms * n
+			}
+			i = next
+		case unicode.IsUpper(r):
+			end := i + 1
+			for end < len(runes) && unicode.IsLower(runes[end]) {
+				end++
+			}
+			symbol := string(runes[i:end])
+			if _, err := GetElement(symbol); err != nil {
+				return nil, fmt.Errorf("formula '%s': %w", formula, err)
+			}
+			n, next, err := count(end)
+			if err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1][symbol] += n
+			i = next
+		default:
+			return nil, fmt.Errorf("formula '%s': unexpected '%c'", formula, r)
+		}
+	}
+
+	if len(closing) > 0 {
+		return nil, fmt.Errorf("formula '%s': missing '%c'", formula, closing[len(closing)-1])
+	}
+	if len(stack[0]) == 0 {
+		return nil, fmt.Errorf("formula '%s' has no elements", formula)
+	}
+	return stack[0], nil
+}
+
+// returns the sum of a property over the atoms of a formula and the number of formula units per Å³
+// at the given mass density in g/cm³
+func formulaSum(formula string, density float64, property func(e Element) float64) (float64, error) {
+	if density < 0 {
+		return 0, fmt.Errorf("negative mass density %f", density)
+	}
+	atoms, err := ParseFormula(formula)
+	if err != nil {
+		return 0, err
+	}
+
+	mass, sum := 0.0, 0.0
+	for symbol, n := range atoms {
+		element := elements[symbol]
+		mass += n * element.Mass
+		sum += n * property(element)
+	}
+
+	// formula units per cm³ to per Å³
+	units := density * AVOGADRO / mass * 1e-24
+	return units * sum, nil
+}
+
+// FormulaEden returns the electron density (electrons/Å³) of a material with the given mass density (g/cm³)
+func FormulaEden(formula string, density float64) (float64, error) {
+	return formulaSum(formula, density, func(e Element) float64 {
+		return float64(e.Z)
+	})
+}
+
+// FormulaXrayEden returns the real part of the x-ray sld in electrons/Å³ at Cu Kα (sum of f1 instead of the electrons)
+// of a material with the given mass density (g/cm³)
+func FormulaXrayEden(formula string, density float64) (float64, error) {
+	return formulaSum(formula, density, func(e Element) float64 {
+		return e.F1
+	})
+}
+
+// FormulaXrayAbsorption returns the imaginary part of the x-ray sld in electrons/Å³ at Cu Kα (sum of f2)
+// of a material with the given mass density (g/cm³), the unit of the absorption parameters
+func FormulaXrayAbsorption(formula string, density float64) (float64, error) {
+	return formulaSum(formula, density, func(e Element) float64 {
+		return e.F2
+	})
+}
+
+// FormulaNeutronSLD returns the coherent neutron scattering length density (1/Å²) of a material
+// with the given mass density (g/cm³)
+func FormulaNeutronSLD(formula string, density float64) (float64, error) {
+	return formulaSum(formula, density, func(e Element) float64 {
+		return e.B * 1e-5 // fm to Å
+	})
+}
+
+// FormulaNeutronAbsorption returns the imaginary part of the neutron sld (1/Å²) of a material with the given mass density (g/cm³)
+// the absorption cross sections scale with the wavelength (1/v law) σ(λ) = σ·λ/NEUTRON_ABSORPTION_WAVELENGTH,
+// so the imaginary sld N·σ(λ)/(2λ) is the same for all wavelengths
+func FormulaNeutronAbsorption(formula string, density float64) (float64, error) {
+	return formulaSum(formula, density, func(e Element) float64 {
+		return e.SigmaAbs * 1e-8 / (2 * NEUTRON_ABSORPTION_WAVELENGTH) // barn to Å²
+	})
+}
+
+// FormulaSLD returns the sld of a material for the radiation in the units of the eden parameters
+// (electron density including anomalous dispersion at Cu Kα for x-rays, neutron sld divided by ELECTRON_RADIUS for neutrons)
+func FormulaSLD(formula string, density float64, radiation Radiation) (float64, error) {
+	switch radiation {
+	case XRAY_RADIATION:
+		return FormulaXrayEden(formula, density)
+	case NEUTRON_RADIATION:
+		sld, err := FormulaNeutronSLD(formula, density)
+		return sld / ELECTRON_RADIUS, err
+	default:
+		return 0, fmt.Errorf("unknown radiation '%s'", radiation)
+	}
+}
+
+// FormulaAbsorption returns the absorption of a material for the radiation in the units of the absorption parameters
+// (f2 at Cu Kα for x-rays, imaginary neutron sld divided by ELECTRON_RADIUS for neutrons)
+func FormulaAbsorption(formula string, density float64, radiation Radiation) (float64, error) {
+	switch radiation {
+	case XRAY_RADIATION:
+		return FormulaXrayAbsorption(formula, density)
+	case NEUTRON_RADIATION:
+		absorption, err := FormulaNeutronAbsorption(formula, density)
+		return absorption / ELECTRON_RADIUS, err
+	default:
+		return 0, fmt.Errorf("unknown radiation '%s'", radiation)
+	}
+}
@@ -0,0 +1,131 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseFormula(t *testing.T) {
+	atoms, err := ParseFormula("Ca(OH)2 [Si0.5O]3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{"Ca": 1, "O": 5, "H": 2, "Si": 1.5}
+	if len(atoms) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, atoms)
+	}
+	for symbol, n := range expected {
+		if math.Abs(atoms[symbol]-n) > 1e-12 {
+			t.Errorf("expected %f %s got %f", n, symbol, atoms[symbol])
+		}
+	}
+
+	for _, invalid := range []string{"", "Xx2", "(H2O", "H2O)", "(H2O]", "h2o", "H0"} {
+		if _, err := ParseFormula(invalid); err == nil {
+			t.Errorf("expected error for formula '%s'", invalid)
+		}
+	}
+}
+
+// tabulated x-ray electron densities and neutron slds of common materials
+func TestFormulaSLD(t *testing.T) {
+	tests := []struct {
+		formula    string
+		density    float64
+		eden       float64
+		neutronSLD float64
+	}{
+		{"H2O", 1.0, 0.3343, -0.560e-6},
+		{"D2O", 1.107, 0.3329, 6.373e-6},
+		{"Si", 2.329, 0.6995, 2.073e-6},
+		{"SiO2", 2.2, 0.6614, 3.475e-6},
+	}
+
+	for _, test := range tests {
+		eden, err := FormulaEden(test.formula, test.density)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(eden-test.eden) > 1e-3 {
+			t.Errorf("%s: expected eden %f got %f", test.formula, test.eden, eden)
+		}
+
+		sld, err := FormulaNeutronSLD(test.formula, test.density)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(sld-test.neutronSLD) > 0.01e-6 {
+			t.Errorf("%s: expected neutron sld %e got %e", test.formula, test.neutronSLD, sld)
+		}
+
+		neutronEden, err := FormulaSLD(test.formula, test.density, NEUTRON_RADIATION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(neutronEden*ELECTRON_RADIUS-sld) > 1e-15 {
+			t.Errorf("%s: neutron eden %f does not match sld %e", test.formula, neutronEden, sld)
+		}
+	}
+}
+
+// tabulated x-ray slds at Cu Kα including anomalous dispersion and absorption
+func TestFormulaXray(t *testing.T) {
+	tests := []struct {
+		formula    string
+		density    float64
+		sld        float64
+		absorption float64
+	}{
+		{"Si", 2.329, 20.07e-6, 0.458e-6},
+		{"SiO2", 2.2, 18.88e-6, 0.245e-6},
+	}
+
+	for _, test := range tests {
+		eden, err := FormulaSLD(test.formula, test.density, XRAY_RADIATION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(eden*ELECTRON_RADIUS-test.sld) > 0.02*test.sld {
+			t.Errorf("%s: expected x-ray sld %e got %e", test.formula, test.sld, eden*ELECTRON_RADIUS)
+		}
+
+		absorption, err := FormulaAbsorption(test.formula, test.density, XRAY_RADIATION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(absorption*ELECTRON_RADIUS-test.absorption) > 0.03*test.absorption {
+			t.Errorf("%s: expected x-ray absorption %e got %e", test.formula, test.absorption, absorption*ELECTRON_RADIUS)
+		}
+	}
+}
+
+// imaginary neutron slds of strong absorbers (N·σ_abs/(2·1.798 Å) with the cross sections of Sears)
+func TestFormulaNeutronAbsorption(t *testing.T) {
+	tests := []struct {
+		formula    string
+		density    float64
+		absorption float64
+	}{
+		{"B4C", 2.52, 2.343e-7},
+		{"Gd2O3", 7.41, 3.403e-6},
+		{"Gd", 7.9, 4.180e-6},
+		{"Si", 2.329, 2.37e-11},
+	}
+
+	for _, test := range tests {
+		absorption, err := FormulaAbsorption(test.formula, test.density, NEUTRON_RADIATION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(absorption*ELECTRON_RADIUS-test.absorption) > 0.01*test.absorption {
+			t.Errorf("%s: expected imaginary neutron sld %e got %e", test.formula, test.absorption, absorption*ELECTRON_RADIUS)
+		}
+	}
+}
+
+func TestElementSymbols(t *testing.T) {
+	symbols := ElementSymbols()
+	if len(symbols) != len(elements) || symbols[0] != "H" || symbols[1] != "D" || symbols[len(symbols)-1] != "U" {
+		t.Errorf("unexpected element order %v", symbols)
+	}
+}
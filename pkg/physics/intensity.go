@@ -10,39 +10,78 @@ import (
 	"sort"
 )
 
-// need more than one? => Copy and rename it. Be careful when to use which axis.
-var qzAxis = GetDefaultQZAxis(qzNumber)
-var qzNumber = 500
+// number of q values of the default qz axis
+const DEFAULT_QZ_NUMBER = 500
 
 type IntensityOptions struct {
 	Background float64
 	Scaling    float64
+
+	// optional absorption profile (see GetAbsorptions), needs to be on the same z axis as the eden points
+	// if nil the sld is purely real
+	Absorption function.Points
+
+	// optional instrumental resolution, if nil the ideal reflectivity is used
+	Resolution *Resolution
+
+	// optional reflectivity engine, if nil the default engine (Parratt) is used
+	Engine ReflectivityEngine
+
+	// optional footprint, the reflectivity is multiplied with the illuminated fraction of the beam, nil disables it
+	Footprint *Footprint
 }
 
-func CalculateIntensityPoints(edenPoints function.Points, deltaq float64, opts *IntensityOptions) function.Points {
-	// transform points into sld floats
-	sld := make([]float64, ZNUMBER)
-	for i, e := range edenPoints {
-		sld[i] = e.Y * ELECTRON_RADIUS
+// returns the selected reflectivity engine or the default engine
+func (opts *IntensityOptions) engine() ReflectivityEngine {
+	if opts == nil || opts.Engine == nil {
+		return DefaultEngine()
+	}
+	return opts.Engine
+}
+
+// CalculateIntensityPoints calculates the intensity of the eden profile on a qz axis
+func CalculateIntensityPoints(edenPoints function.Points, qzaxis []float64, deltaq float64, opts *IntensityOptions) (function.Points, error) {
+	var absorptionPoints function.Points
+	if opts != nil {
+		absorptionPoints = opts.Absorption
 	}
 
-	deltaz := 0.0
+	// transform points into complex slds
+	sld, err := GetSLDs(edenPoints, absorptionPoints)
+	if err != nil {
+		return nil, err
+	}
 
-	if edenPoints != nil && len(edenPoints) > 1 {
-		deltaz = edenPoints[1].X - edenPoints[0].X
+	// slice thicknesses from the z values, the eden points do not need to be equidistant
+	stack, err := NewProfileStack(helper.Map(edenPoints, func(p *function.Point) float64 { return p.X }), sld)
+	if err != nil {
+		return nil, err
 	}
 
+	return CalculateStackIntensityPoints(stack, qzaxis, deltaq, opts), nil
+}
+
+// CalculateStackIntensityPoints calculates the intensity of a stack (e.g. a slab model) on a qz axis
+//
+// the absorption profile of the options is ignored, the absorption is part of the slds of the stack
+func CalculateStackIntensityPoints(stack *Stack, qzaxis []float64, deltaq float64, opts *IntensityOptions) function.Points {
 	// calculate intensity
 
-	modifiedQzAxis := helper.Map(qzAxis, func(xPoint float64) float64 { return xPoint + deltaq })
+	modifiedQzAxis := helper.Map(qzaxis, func(xPoint float64) float64 { return xPoint + deltaq })
 
-	intensity := CalculateIntensity(modifiedQzAxis, deltaz, sld, opts)
+	// resolution widths belong to the unshifted (measured) q values
+	var widths []float64
+	if opts != nil {
+		widths = opts.Resolution.Widths(qzaxis)
+	}
+
+	intensity := calculateIntensity(modifiedQzAxis, widths, stack, opts)
 
 	// creates list with intensity points based on edenPoints x and error and calculated intensity as y
-	intensityPoints := make(function.Points, qzNumber)
+	intensityPoints := make(function.Points, len(qzaxis))
 	for i := range intensity {
 		intensityPoints[i] = &function.Point{
-			X:     qzAxis[i],
+			X:     qzaxis[i],
 			Y:     intensity[i],
 			Error: 0.0,
 		}
@@ -51,26 +90,63 @@ func CalculateIntensityPoints(edenPoints function.Points, deltaq float64, opts *
 	return intensityPoints
 }
 
+// GetSLDs converts the eden profile and the optional absorption profile into complex slds
+//
+// the imaginary part is negative for absorbing media: sld = (eden - i*absorption) * ELECTRON_RADIUS
+func GetSLDs(edenPoints, absorptionPoints function.Points) ([]complex128, error) {
+	if absorptionPoints != nil && len(absorptionPoints) != len(edenPoints) {
+		return nil, fmt.Errorf("absorption profile has the wrong length: %d vs %d", len(absorptionPoints), len(edenPoints))
+	}
+
+	sld := make([]complex128, len(edenPoints))
+	for i, e := range edenPoints {
+		absorption := 0.0
+		if absorptionPoints != nil {
+			absorption = absorptionPoints[i].Y
+		}
+		sld[i] = complex(e.Y*ELECTRON_RADIUS, -absorption*ELECTRON_RADIUS)
+	}
+
+	return sld, nil
+}
+
 // CalculateIntensity calculates intensity from the slds
-func CalculateIntensity(qzaxis []float64, deltaz float64, sld []float64, opts *IntensityOptions) []float64 {
-	// Get reflectivity values
-	refl := CalculateReflectivity(qzaxis, deltaz, sld)
+func CalculateIntensity(qzaxis []float64, deltaz float64, sld []complex128, opts *IntensityOptions) []float64 {
+	var widths []float64
+	if opts != nil {
+		widths = opts.Resolution.Widths(qzaxis)
+	}
 
-	// return reflectivity if no options are given (default: scaling=1, background=0)
+	return calculateIntensity(qzaxis, widths, NewSlicedStack(deltaz, sld), opts)
+}
+
+// calculateIntensity calculates intensity of the stack with the resolution widths for each q value
+func calculateIntensity(qzaxis []float64, widths []float64, stack *Stack, opts *IntensityOptions) []float64 {
+	engine := opts.engine()
+
+	// return reflectivity if no options are given (default: scaling=1, background=0, no resolution)
 	if opts == nil {
-		return refl
+		return engine.Reflectivity(qzaxis, stack)
 	}
 
-	// Calculate intensity with scaling and background
+	// Get reflectivity values, smeared by the resolution
+	var refl []float64
+	if opts.Resolution != nil {
+		refl = CalculateSmearedReflectivity(engine, qzaxis, widths, stack)
+	} else {
+		refl = engine.Reflectivity(qzaxis, stack)
+	}
+
+	// Calculate intensity with footprint, scaling and background
 	intensity := make([]float64, len(refl))
 	for i := range refl {
-		intensity[i] = opts.Scaling*refl[i] + opts.Background
+		intensity[i] = opts.Scaling*refl[i]*opts.Footprint.Factor(qzaxis[i]) + opts.Background
 	}
 
 	return intensity
 }
 
-// calculates reflectivity using the Parratt formalism
+// calculates reflectivity using the Parratt formalism for real (non absorbing) slds
 //
 // qzaxis: momentum transfer values
 //
@@ -78,65 +154,71 @@ func CalculateIntensity(qzaxis []float64, deltaz float64, sld []float64, opts *I
 //
 // sld: scattering length densities
 func CalculateReflectivity(qzaxis []float64, deltaz float64, sld []float64) []float64 {
+	return CalculateComplexReflectivity(qzaxis, deltaz, helper.Map(sld, func(s float64) complex128 { return complex(s, 0) }))
+}
+
+// calculates reflectivity using the Parratt formalism
+//
+// qzaxis: momentum transfer values
+//
+// deltaz: layer thicknesses
+//
+// sld: complex scattering length densities, a negative imaginary part describes absorption
+func CalculateComplexReflectivity(qzaxis []float64, deltaz float64, sld []complex128) []float64 {
+	return CalculateParrattReflectivity(qzaxis, NewSlicedStack(deltaz, sld))
+}
+
+// calculates reflectivity of a stack using the Parratt recursion
+//
+// the partial reflectivity amplitudes are calculated from the substrate upwards,
+// fresnel coefficients of rough interfaces are damped by the roughness factors of the stack
+func CalculateParrattReflectivity(qzaxis []float64, stack *Stack) []float64 {
 	ci := complex(0, 1.0)
 	c1 := complex(1.0, 0)
-	c0 := complex(0, 0)
 
-	qznumber := len(qzaxis)
-	nmedia := len(sld)
-	ninterfaces := nmedia - 1
-	nslabs := nmedia - 2
+	nmedia := len(stack.SLD)
 
 	// Initialize output array
-	refl := make([]float64, qznumber)
+	refl := make([]float64, len(qzaxis))
+	if nmedia < 2 {
+		return refl
+	}
 
 	// Calculate reflectivity for each q value
-	for iq := 0; iq < qznumber; iq++ {
-		k0 := complex(qzaxis[iq]/2.0, 0)
+	for iq, q := range qzaxis {
+		k := stack.waveVectors(q / 2.0)
 
-		// Calculate wave vectors
-		k := make([]complex128, nmedia)
-		for i := 0; i < nmedia; i++ {
-			k[i] = cmplx.Sqrt(complex(math.Pow(real(k0), 2)-4.0*math.Pi*(sld[i]-sld[0]), 0))
-		}
-
-		// Calculate Fresnel coefficients
-		rfres := make([]complex128, ninterfaces)
-		for i := 0; i < ninterfaces; i++ {
-			rfres[i] = (k[i] - k[i+1]) / (k[i] + k[i+1])
-		}
-
-		// Calculate phase factors
-		hphase := make([]complex128, nslabs)
-		fphase := make([]complex128, nslabs)
-		for i := 0; i < nslabs; i++ {
-			hphase[i] = cmplx.Exp(ci * k[i+1] * complex(deltaz, 0))
-			fphase[i] = cmplx.Exp(2.0 * ci * k[i+1] * complex(deltaz, 0))
-		}
+		// the substrate has no reflected wave, its interface gives the fresnel coefficient
+		rparr := stack.fresnel(k, nmedia-2)
 
 		// Calculate partial reflectivity amplitudes
-		rparr := make([]complex128, nmedia)
-		for i := 0; i < nmedia; i++ {
-			i2 := nmedia - 1 - i
-
-			if i >= 2 {
-				numerator := rfres[i2] + rparr[i2+1]*fphase[i2]
-				denominator := c1 + rfres[i2]*rparr[i2+1]*fphase[i2]
-				rparr[i2] = numerator / denominator
-			} else if i == 1 {
-				rparr[i2] = rfres[i2]
-			} else if i == 0 {
-				rparr[i2] = c0
-			}
+		for i := nmedia - 3; i >= 0; i-- {
+			rfres := stack.fresnel(k, i)
+			fphase := cmplx.Exp(2.0 * ci * k[i+1] * complex(stack.Thickness[i], 0))
+
+			rparr = (rfres + rparr*fphase) / (c1 + rfres*rparr*fphase)
 		}
 
 		// Calculate final reflectivity
-		refl[iq] = math.Pow(cmplx.Abs(rparr[0]), 2)
+		refl[iq] = math.Pow(cmplx.Abs(rparr), 2)
 	}
 
 	return refl
 }
 
+// waveVector calculates the z component of the wave vector inside a medium
+// with an sld difference of dsld to the ambient medium
+func waveVector(k0 float64, dsld complex128) complex128 {
+	kz2 := complex(k0*k0, 0) - 4.0*math.Pi*dsld
+
+	// a negative zero imaginary part would select the wrong branch of the square root
+	if imag(kz2) == 0 {
+		kz2 = complex(real(kz2), 0)
+	}
+
+	return cmplx.Sqrt(kz2)
+}
+
 func GetDefaultQZAxis(qzNumber int) []float64 {
 	qzAxis := make([]float64, qzNumber)
 	for i := 0; i < qzNumber; i++ {
@@ -145,36 +227,39 @@ func GetDefaultQZAxis(qzNumber int) []float64 {
 	return qzAxis
 }
 
-// use the combined experimental axis as current qz axis
-func AlterQZAxis(dataSets function.Functions, graphID string) {
-	if graphID == "intensity" {
-		var qzValues []float64
-		for _, dataSet := range dataSets {
-			for _, point := range dataSet.GetData() {
-				qzValues = append(qzValues, point.X)
-			}
+// GetDataQZAxis returns the sorted q values of all data sets, the default qz axis if there are no data points
+func GetDataQZAxis(dataSets []function.Points) []float64 {
+	var qzValues []float64
+	for _, dataSet := range dataSets {
+		for _, point := range dataSet {
+			qzValues = append(qzValues, point.X)
 		}
-		sort.Float64s(qzValues)
-		qzValues = slices.Compact(qzValues)
-		qzAxis = qzValues
-		qzNumber = len(qzAxis)
+	}
+	if len(qzValues) == 0 {
+		return GetDefaultQZAxis(DEFAULT_QZ_NUMBER)
 	}
 
+	sort.Float64s(qzValues)
+	return slices.Compact(qzValues)
 }
 
-// calculate a penalty between the calculated intensity and the loaded data sets
+// calculate a penalty between the calculated intensity and the loaded data sets with the default cost function
 func Sim2SigRMS(dataSets []function.Points, intensity function.Points) (float64, error) {
-	if len(intensity) != qzNumber {
-		return math.MaxFloat64, fmt.Errorf("rms calculation: intensity slice has the wrong length: %d vs %d", len(intensity), qzNumber)
-	}
+	return CalculateCost(DefaultCostFunction(), dataSets, intensity)
+}
+
+// calculate the sum of the costs of all points of the loaded data sets for the calculated intensity
+// the intensity is interpolated at the q of every data point, so the data sets do not need to share the qz axis of the intensity
+func CalculateCost(cost CostFunction, dataSets []function.Points, intensity function.Points) (float64, error) {
 	var diff float64
 	for _, dataSet := range dataSets {
 		for _, point := range dataSet {
-			y_intensity, err := function.GetY(intensity, point.X)
+			// the logarithm is interpolated, since the reflectivity decays over decades
+			y_intensity, err := function.LogInterpolate(intensity, point.X)
 			if err != nil {
 				return math.MaxFloat64, fmt.Errorf("rms calculation: there is no intensity for: %f", point.X)
 			}
-			diff += math.Pow(point.X, 2) * math.Pow((y_intensity-point.Y)/point.Error, 2)
+			diff += cost.Cost(point, y_intensity)
 		}
 	}
 	return diff, nil
@@ -0,0 +1,108 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"slices"
+	"testing"
+)
+
+// default layer model of the gui
+var (
+	testEden  = []float64{0.0, 0.346197, 0.458849, 0.334000}
+	testD     = []float64{14.2657, 10.6906}
+	testSigma = []float64{3.39544, 2.15980, 3.90204}
+)
+
+// a complex sld without imaginary part needs to give the same result as the real calculation
+func TestComplexReflectivityRealSpecialCase(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	realSLD := make([]float64, len(edenPoints))
+	for i, p := range edenPoints {
+		realSLD[i] = p.Y * ELECTRON_RADIUS
+	}
+	complexSLD, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qz := GetDefaultQZAxis(500)
+	deltaz := edenPoints[1].X - edenPoints[0].X
+
+	realRefl := CalculateReflectivity(qz, deltaz, realSLD)
+	complexRefl := CalculateComplexReflectivity(qz, deltaz, complexSLD)
+
+	for i := range qz {
+		if math.Abs(realRefl[i]-complexRefl[i]) > 1e-12 {
+			t.Errorf("reflectivity differs at q=%f: %g vs %g", qz[i], realRefl[i], complexRefl[i])
+		}
+	}
+}
+
+// absorption removes the total reflection below the critical edge
+func TestAbsorptionReducesReflectivity(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	absorptionPoints, err := GetAbsorptions([]float64{0, 0, 0, 0.01}, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sld, err := GetSLDs(edenPoints, absorptionPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qz := []float64{0.01, 0.015}
+	deltaz := edenPoints[1].X - edenPoints[0].X
+	refl := CalculateComplexReflectivity(qz, deltaz, sld)
+	reference := CalculateComplexReflectivity(qz, deltaz, helperRealSLD(sld))
+
+	for i := range qz {
+		if math.Abs(reference[i]-1) > 1e-9 {
+			t.Errorf("expected total reflection at q=%f without absorption, got %g", qz[i], reference[i])
+		}
+		if refl[i] >= reference[i] {
+			t.Errorf("expected absorption to reduce reflectivity at q=%f: %g vs %g", qz[i], refl[i], reference[i])
+		}
+	}
+}
+
+func helperRealSLD(sld []complex128) []complex128 {
+	res := make([]complex128, len(sld))
+	for i, s := range sld {
+		res[i] = complex(real(s), 0)
+	}
+	return res
+}
+
+func TestAbsorptionLengthMismatch(t *testing.T) {
+	edenPoints, _ := GetEdensities(testEden, testD, testSigma)
+	if _, err := GetSLDs(edenPoints, edenPoints[:10]); err == nil {
+		t.Error("expected an error for mismatching profile lengths")
+	}
+	if _, err := GetAbsorptions([]float64{0, 0}, testD, testSigma); err == nil {
+		t.Error("expected an error for a wrong number of absorption values")
+	}
+}
+
+// the data axis contains every q value once and sorted, without data the default axis is used
+func TestGetDataQZAxis(t *testing.T) {
+	dataSets := []function.Points{
+		{{X: 0.3}, {X: 0.1}},
+		{{X: 0.2}, {X: 0.1}},
+	}
+	if qz := GetDataQZAxis(dataSets); !slices.Equal(qz, []float64{0.1, 0.2, 0.3}) {
+		t.Errorf("expected [0.1 0.2 0.3] got %v", qz)
+	}
+
+	if qz := GetDataQZAxis(nil); len(qz) != DEFAULT_QZ_NUMBER {
+		t.Errorf("expected the default axis got %d values", len(qz))
+	}
+}
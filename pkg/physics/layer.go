@@ -0,0 +1,275 @@
+package physics
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// parameter groups generated by a layer stack
+const (
+	EDEN_GROUP       = "eden"
+	THICKNESS_GROUP  = "thick"
+	ROUGHNESS_GROUP  = "rough"
+	ABSORPTION_GROUP = "absorb"
+	MAGNETIC_GROUP   = "magnetic"
+	ANGLE_GROUP      = "angle"
+	REPEAT_GROUP     = "repeat"
+)
+
+// default values for parameters of newly created layers
+const (
+	DEFAULT_EDEN       = 0.334
+	DEFAULT_THICKNESS  = 10.0
+	DEFAULT_ROUGHNESS  = 3.0
+	DEFAULT_ABSORPTION = 0.0
+	DEFAULT_MAGNETIC   = 0.0
+	DEFAULT_ANGLE      = 0.0
+	DEFAULT_DRIFT      = 0.0
+)
+
+// ParameterSpec describes a parameter a model needs, so it can be registered in the gui
+type ParameterSpec struct {
+	Group   string
+	Name    string
+	Default float64
+}
+
+// LayerStack describes a model of n layers between the ambient medium (a) and the substrate (b)
+//
+// every medium has an eden and an absorption value, every layer a thickness
+// and every interface between two media a roughness
+//
+// magnetic stacks additionally have a magnetic sld (same units as eden) and an in-plane angle (degree)
+// of the magnetisation for every layer and the substrate, the ambient medium is non magnetic
+//
+// an optional repeat unit (superlattice [1/.../m]×N) of RepeatLayers layers is inserted between the last layer
+// and the substrate, every layer of the unit has its own eden, thickness, roughness (to the medium above) and absorption
+type LayerStack struct {
+	Layers   int
+	Magnetic bool
+
+	// number of layers of the repeat unit, 0 for stacks without repeat unit
+	RepeatLayers int
+}
+
+// LayerValues holds the parameter values of a layer stack split into the single groups
+type LayerValues struct {
+	// eden {a,1,...,n,b}
+	Eden []float64
+	// thickness {1,...,n}
+	Thickness []float64
+	// roughness {a/1,...,n/b}
+	Roughness []float64
+	// absorption {a,1,...,n,b}
+	Absorption []float64
+
+	// magnetic sld {1,...,n,b} and in-plane angle {1,...,n,b}, nil for non magnetic stacks
+	Magnetic []float64
+	Angle    []float64
+
+	// values of the repeat unit, nil for stacks without repeat unit
+	Repeat *RepeatValues
+}
+
+// RepeatValues holds the parameter values of the layers {1,...,m} of a repeat unit
+type RepeatValues struct {
+	Eden       []float64
+	Thickness  []float64
+	Roughness  []float64
+	Absorption []float64
+
+	// nil for non magnetic stacks
+	Magnetic []float64
+	Angle    []float64
+
+	// relative change of the thicknesses per period, period p (starting at 0) has the thicknesses d*(1+p*drift)
+	Drift float64
+}
+
+// creates a new layer stack with n layers between ambient medium and substrate
+func NewLayerStack(layers int) *LayerStack {
+	return &LayerStack{
+		Layers: max(layers, 0),
+	}
+}
+
+// creates a new magnetic layer stack with n layers between ambient medium and substrate
+func NewMagneticLayerStack(layers int) *LayerStack {
+	return &LayerStack{
+		Layers:   max(layers, 0),
+		Magnetic: true,
+	}
+}
+
+// returns the name of the medium with index i (0 = ambient medium, n+1 = substrate)
+func (s *LayerStack) mediumName(i int) string {
+	switch i {
+	case 0:
+		return "a"
+	case s.Layers + 1:
+		return "b"
+	default:
+		return strconv.Itoa(i)
+	}
+}
+
+// returns the name of the roughness parameter between medium i and i+1
+func (s *LayerStack) RoughnessName(i int) string {
+	return fmt.Sprintf("Roughness %s/%s", s.mediumName(i), s.mediumName(i+1))
+}
+
+// returns all parameters of the stack in the order expected by Split
+//
+// order: eden {a,1,...,n,b}, thickness {1,...,n}, roughness {a/1,...,n/b}, absorption {a,1,...,n,b}
+// followed by magnetic {1,...,n,b} and angle {1,...,n,b} for magnetic stacks
+// and the repeat unit (eden, thickness, roughness, absorption and for magnetic stacks magnetic and angle {1,...,m}, drift)
+func (s *LayerStack) Parameters() []ParameterSpec {
+	specs := make([]ParameterSpec, 0, s.ParameterCount())
+
+	for i := 0; i < s.Layers+2; i++ {
+		specs = append(specs, ParameterSpec{EDEN_GROUP, "Eden " + s.mediumName(i), DEFAULT_EDEN})
+	}
+	for i := 1; i <= s.Layers; i++ {
+		specs = append(specs, ParameterSpec{THICKNESS_GROUP, "Thickness " + s.mediumName(i), DEFAULT_THICKNESS})
+	}
+	for i := 0; i < s.Layers+1; i++ {
+		specs = append(specs, ParameterSpec{ROUGHNESS_GROUP, s.RoughnessName(i), DEFAULT_ROUGHNESS})
+	}
+	for i := 0; i < s.Layers+2; i++ {
+		specs = append(specs, ParameterSpec{ABSORPTION_GROUP, "Absorption " + s.mediumName(i), DEFAULT_ABSORPTION})
+	}
+
+	if s.Magnetic {
+		for i := 1; i < s.Layers+2; i++ {
+			specs = append(specs, ParameterSpec{MAGNETIC_GROUP, "Magnetic " + s.mediumName(i), DEFAULT_MAGNETIC})
+		}
+		for i := 1; i < s.Layers+2; i++ {
+			specs = append(specs, ParameterSpec{ANGLE_GROUP, "Angle " + s.mediumName(i), DEFAULT_ANGLE})
+		}
+	}
+
+	if s.RepeatLayers > 0 {
+		names := []string{"Eden", "Thickness", "Roughness", "Absorption"}
+		defaults := []float64{DEFAULT_EDEN, DEFAULT_THICKNESS, DEFAULT_ROUGHNESS, DEFAULT_ABSORPTION}
+		if s.Magnetic {
+			names = append(names, "Magnetic", "Angle")
+			defaults = append(defaults, DEFAULT_MAGNETIC, DEFAULT_ANGLE)
+		}
+		for n, name := range names {
+			for i := 1; i <= s.RepeatLayers; i++ {
+				specs = append(specs, ParameterSpec{REPEAT_GROUP, fmt.Sprintf("Repeat %s %d", name, i), defaults[n]})
+			}
+		}
+		specs = append(specs, ParameterSpec{REPEAT_GROUP, "Repeat Drift", DEFAULT_DRIFT})
+	}
+
+	return specs
+}
+
+// returns the number of parameters of the stack
+func (s *LayerStack) ParameterCount() int {
+	groups, count := 4, 4*s.Layers+5
+	if s.Magnetic {
+		groups, count = 6, 6*s.Layers+7
+	}
+	if s.RepeatLayers > 0 {
+		count += groups*s.RepeatLayers + 1
+	}
+	return count
+}
+
+// splits the parameter values (ordered like Parameters) into the single groups
+func (s *LayerStack) Split(params []float64) (*LayerValues, error) {
+	if len(params) != s.ParameterCount() {
+		return nil, fmt.Errorf("layer stack with %d layers expects %d parameters but got %d", s.Layers, s.ParameterCount(), len(params))
+	}
+
+	n := s.Layers
+	values := &LayerValues{
+		Eden:       params[0 : n+2],
+		Thickness:  params[n+2 : 2*n+2],
+		Roughness:  params[2*n+2 : 3*n+3],
+		Absorption: params[3*n+3 : 4*n+5],
+	}
+	next := 4*n + 5
+	if s.Magnetic {
+		values.Magnetic = params[4*n+5 : 5*n+6]
+		values.Angle = params[5*n+6 : 6*n+7]
+		next = 6*n + 7
+	}
+
+	if m := s.RepeatLayers; m > 0 {
+		values.Repeat = &RepeatValues{
+			Eden:       params[next : next+m],
+			Thickness:  params[next+m : next+2*m],
+			Roughness:  params[next+2*m : next+3*m],
+			Absorption: params[next+3*m : next+4*m],
+		}
+		next += 4 * m
+		if s.Magnetic {
+			values.Repeat.Magnetic = params[next : next+m]
+			values.Repeat.Angle = params[next+m : next+2*m]
+			next += 2 * m
+		}
+		values.Repeat.Drift = params[next]
+	}
+
+	return values, nil
+}
+
+// Expand returns the values of the stack with the repeat unit inserted repetitions times above the substrate
+// the result has no repeat unit, so it can be passed to GetEdensities, NewSlabStack and the other profile functions
+//
+// the roughness between two periods is the roughness of the first layer of the unit,
+// the last period keeps the substrate roughness of the stack
+func (v *LayerValues) Expand(repetitions int) *LayerValues {
+	if v.Repeat == nil || repetitions <= 0 {
+		return &LayerValues{
+			Eden:       v.Eden,
+			Thickness:  v.Thickness,
+			Roughness:  v.Roughness,
+			Absorption: v.Absorption,
+			Magnetic:   v.Magnetic,
+			Angle:      v.Angle,
+		}
+	}
+
+	n := len(v.Thickness)
+	r := v.Repeat
+	expanded := &LayerValues{
+		Eden:       slices.Clone(v.Eden[:n+1]),
+		Thickness:  slices.Clone(v.Thickness),
+		Roughness:  slices.Clone(v.Roughness[:n]),
+		Absorption: slices.Clone(v.Absorption[:n+1]),
+	}
+	if v.Magnetic != nil {
+		expanded.Magnetic = slices.Clone(v.Magnetic[:n])
+		expanded.Angle = slices.Clone(v.Angle[:n])
+	}
+
+	for p := range repetitions {
+		drift := 1 + float64(p)*r.Drift
+		for i := range r.Thickness {
+			expanded.Eden = append(expanded.Eden, r.Eden[i])
+			expanded.Thickness = append(expanded.Thickness, r.Thickness[i]*drift)
+			expanded.Roughness = append(expanded.Roughness, r.Roughness[i])
+			expanded.Absorption = append(expanded.Absorption, r.Absorption[i])
+			if v.Magnetic != nil {
+				expanded.Magnetic = append(expanded.Magnetic, r.Magnetic[i])
+				expanded.Angle = append(expanded.Angle, r.Angle[i])
+			}
+		}
+	}
+
+	// substrate
+	expanded.Eden = append(expanded.Eden, v.Eden[n+1])
+	expanded.Roughness = append(expanded.Roughness, v.Roughness[n])
+	expanded.Absorption = append(expanded.Absorption, v.Absorption[n+1])
+	if v.Magnetic != nil {
+		expanded.Magnetic = append(expanded.Magnetic, v.Magnetic[n])
+		expanded.Angle = append(expanded.Angle, v.Angle[n])
+	}
+
+	return expanded
+}
@@ -0,0 +1,163 @@
+package physics
+
+import (
+	"cmp"
+	"math"
+	"physicsGUI/pkg/function"
+	"slices"
+	"testing"
+)
+
+func TestLayerStackParameters(t *testing.T) {
+	stack := NewLayerStack(2)
+
+	specs := stack.Parameters()
+	if len(specs) != stack.ParameterCount() {
+		t.Fatalf("expected %d parameters got %d", stack.ParameterCount(), len(specs))
+	}
+
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+	expected := []string{
+		"Eden a", "Eden 1", "Eden 2", "Eden b",
+		"Thickness 1", "Thickness 2",
+		"Roughness a/1", "Roughness 1/2", "Roughness 2/b",
+		"Absorption a", "Absorption 1", "Absorption 2", "Absorption b",
+	}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected %v got %v", expected, names)
+	}
+}
+
+func TestLayerStackSplit(t *testing.T) {
+	for n := 0; n < 5; n++ {
+		for _, stack := range []*LayerStack{NewLayerStack(n), NewMagneticLayerStack(n)} {
+			specs := stack.Parameters()
+			if len(specs) != stack.ParameterCount() {
+				t.Fatalf("expected %d parameters got %d", stack.ParameterCount(), len(specs))
+			}
+
+			values, err := stack.Split(make([]float64, stack.ParameterCount()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(values.Eden) != n+2 || len(values.Thickness) != n || len(values.Roughness) != n+1 || len(values.Absorption) != n+2 {
+				t.Errorf("wrong group sizes for %d layers: %d %d %d %d", n, len(values.Eden), len(values.Thickness), len(values.Roughness), len(values.Absorption))
+			}
+			if stack.Magnetic && (len(values.Magnetic) != n+1 || len(values.Angle) != n+1) {
+				t.Errorf("wrong magnetic group sizes for %d layers: %d %d", n, len(values.Magnetic), len(values.Angle))
+			}
+
+			// the groups have to fit the profile calculation
+			if _, err := GetEdensities(values.Eden, values.Thickness, values.Roughness); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	if _, err := NewLayerStack(2).Split(make([]float64, 3)); err == nil {
+		t.Error("expected an error for a wrong parameter count")
+	}
+}
+
+func TestLayerStackRepeatUnit(t *testing.T) {
+	stack := &LayerStack{Layers: 1, RepeatLayers: 2}
+
+	specs := stack.Parameters()
+	if len(specs) != stack.ParameterCount() {
+		t.Fatalf("expected %d parameters got %d", stack.ParameterCount(), len(specs))
+	}
+
+	params := make([]float64, stack.ParameterCount())
+	for i := range params {
+		params[i] = float64(i)
+	}
+	values, err := stack.Split(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values.Repeat.Drift = 0.1
+
+	// a, 1, [r1, r2]x3, b
+	expanded := values.Expand(3)
+	if len(expanded.Eden) != 9 || len(expanded.Thickness) != 7 || len(expanded.Roughness) != 8 || len(expanded.Absorption) != 9 {
+		t.Fatalf("wrong group sizes: %d %d %d %d", len(expanded.Eden), len(expanded.Thickness), len(expanded.Roughness), len(expanded.Absorption))
+	}
+	if expanded.Eden[2] != values.Repeat.Eden[0] || expanded.Eden[7] != values.Repeat.Eden[1] || expanded.Eden[8] != values.Eden[2] {
+		t.Errorf("wrong expanded eden %v", expanded.Eden)
+	}
+	if expanded.Roughness[7] != values.Roughness[1] || expanded.Roughness[3] != values.Repeat.Roughness[0] {
+		t.Errorf("wrong expanded roughness %v", expanded.Roughness)
+	}
+	if drift := values.Repeat.Thickness[1] * 1.2; math.Abs(expanded.Thickness[6]-drift) > 1e-12 {
+		t.Errorf("expected drifted thickness %f got %f", drift, expanded.Thickness[6])
+	}
+
+	// no repetitions leaves the stack
+	if plain := values.Expand(0); !slices.Equal(plain.Eden, values.Eden) {
+		t.Errorf("expected %v got %v", values.Eden, plain.Eden)
+	}
+}
+
+// a superlattice gives a bragg peak at q = 2*pi/period
+func TestRepeatUnitBraggPeak(t *testing.T) {
+	stack := &LayerStack{RepeatLayers: 2}
+	params := make([]float64, stack.ParameterCount())
+	values, err := stack.Split(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(values.Eden, []float64{0, 0.334})
+	copy(values.Repeat.Eden, []float64{0.8, 0.4})
+	copy(values.Repeat.Thickness, []float64{20, 30})
+	copy(values.Repeat.Roughness, []float64{2, 2})
+
+	expanded := values.Expand(20)
+	slab, err := NewSlabStack(expanded.Eden, expanded.Thickness, expanded.Roughness, expanded.Absorption, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peak := 2 * math.Pi / 50
+	maxInRange := func(from, to float64) float64 {
+		qz := make([]float64, 0)
+		for q := from; q < to; q += 0.0002 {
+			qz = append(qz, q)
+		}
+		return slices.Max(CalculateParrattReflectivity(qz, slab))
+	}
+	if bragg, between := maxInRange(peak-0.005, peak+0.005), maxInRange(peak+0.03, peak+0.06); bragg < 100*between {
+		t.Errorf("expected a bragg peak at %f: %g vs %g", peak, bragg, between)
+	}
+
+	// the microsliced profile of a thick superlattice needs to resolve the repeat unit,
+	// its bragg peaks (first and third order) are compared to the slab calculation of the same stack
+	expanded = values.Expand(40)
+	slab, err = NewSlabStack(expanded.Eden, expanded.Thickness, expanded.Roughness, expanded.Absorption, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edenPoints, err := GetEdensities(expanded.Eden, expanded.Thickness, expanded.Roughness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range []float64{1, 3} {
+		qz := make([]float64, 0)
+		for q := order*peak - 0.02; q < order*peak+0.02; q += 0.0001 {
+			qz = append(qz, q)
+		}
+		sliced, err := CalculateIntensityPoints(edenPoints, qz, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		highest := slices.MaxFunc(sliced, func(a, b *function.Point) int { return cmp.Compare(a.Y, b.Y) }).X
+		exact := CalculateParrattReflectivity(qz, slab)
+		expected := qz[slices.Index(exact, slices.Max(exact))]
+		if math.Abs(highest-expected) > 0.0005 {
+			t.Errorf("expected the bragg peak of order %.0f at %f got %f", order, expected, highest)
+		}
+	}
+}
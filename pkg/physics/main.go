@@ -3,4 +3,6 @@ package physics
 const (
 	ELECTRON_RADIUS = 2.81e-5 // classical electron radius in angstrom
 	ZNUMBER         = 150
+	// upper bound of the slice thickness of the default z axis in angstrom (see microsliceNumber)
+	MAX_MICROSLICE = 2.0
 )
@@ -0,0 +1,258 @@
+package physics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/helper"
+)
+
+// spin channels of polarised neutron reflectivity, the first sign is the incident and the second the reflected spin
+const (
+	UP_UP = iota
+	DOWN_DOWN
+	UP_DOWN
+	DOWN_UP
+)
+
+// names of the spin channels in the order of the channel constants
+var ChannelNames = []string{"++", "--", "+-", "-+"}
+
+// GetMagneticProfiles returns the magnetic profile split into the components parallel and perpendicular to the polarisation axis
+// - zaxis are the z values of the profile, e.g. the x values of the eden profile
+// - magnetic {m_1,...,m_n,m_b} magnetic sld (same units as eden), the ambient medium is non magnetic
+// - angle {θ_1,...,θ_n,θ_b} in-plane angle between magnetisation and polarisation axis in degree
+// - d and sigma are the same as for GetEdensities
+//
+// the components are interpolated by error function steps, so the magnetisation rotates smoothly at rough interfaces
+func GetMagneticProfiles(zaxis []float64, magnetic, angle, d, sigma []float64) (parallel, perpendicular function.Points, err error) {
+	if len(magnetic) != len(d)+1 || len(angle) != len(d)+1 {
+		return nil, nil, fmt.Errorf("missmatch in parameter dimensionality magnetic %d/angle %d/thickness %d", len(magnetic), len(angle), len(d))
+	}
+	if len(sigma) != len(d)+1 {
+		return nil, nil, fmt.Errorf("missmatch in parameter dimensionality roughness %d/thickness %d", len(sigma), len(d))
+	}
+
+	par := make([]float64, len(magnetic)+1)
+	perp := make([]float64, len(magnetic)+1)
+	for i := range magnetic {
+		rad := angle[i] * math.Pi / 180
+		par[i+1] = magnetic[i] * math.Cos(rad)
+		perp[i+1] = magnetic[i] * math.Sin(rad)
+	}
+
+	return getErfProfileOnAxis(zaxis, par, d, sigma), getErfProfileOnAxis(zaxis, perp, d, sigma), nil
+}
+
+// CalculatePolarisedIntensityPoints calculates the intensity of all spin channels on a qz axis
+//
+// the magnetic profiles (see GetMagneticProfiles) need to be on the same z axis as the eden points,
+// the returned points are ordered like the channel constants
+func CalculatePolarisedIntensityPoints(edenPoints, parallel, perpendicular function.Points, qzaxis []float64, deltaq float64, opts *IntensityOptions) ([]function.Points, error) {
+	if len(parallel) != len(edenPoints) || len(perpendicular) != len(edenPoints) {
+		return nil, fmt.Errorf("magnetic profile has the wrong length: %d/%d vs %d", len(parallel), len(perpendicular), len(edenPoints))
+	}
+
+	var absorptionPoints function.Points
+	if opts != nil {
+		absorptionPoints = opts.Absorption
+	}
+	sld, err := GetSLDs(edenPoints, absorptionPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	stack, err := NewProfileStack(helper.Map(edenPoints, func(p *function.Point) float64 { return p.X }), sld)
+	if err != nil {
+		return nil, err
+	}
+	stack.MagneticParallel = helper.Map(parallel, func(p *function.Point) float64 { return p.Y * ELECTRON_RADIUS })
+	stack.MagneticPerpendicular = helper.Map(perpendicular, func(p *function.Point) float64 { return p.Y * ELECTRON_RADIUS })
+
+	modifiedQzAxis := helper.Map(qzaxis, func(xPoint float64) float64 { return xPoint + deltaq })
+
+	// reflectivity of all channels, smeared by the resolution
+	var channels [][]float64
+	if opts != nil && opts.Resolution != nil {
+		channels = smearChannels(modifiedQzAxis, opts.Resolution.Widths(qzaxis), func(sampled []float64) [][]float64 {
+			return CalculatePolarisedReflectivity(sampled, stack)
+		})
+	} else {
+		channels = CalculatePolarisedReflectivity(modifiedQzAxis, stack)
+	}
+
+	points := make([]function.Points, len(channels))
+	for c, refl := range channels {
+		points[c] = make(function.Points, len(refl))
+		for i := range refl {
+			y := refl[i]
+			if opts != nil {
+				y = opts.Scaling*refl[i]*opts.Footprint.Factor(modifiedQzAxis[i]) + opts.Background
+			}
+			points[c][i] = &function.Point{
+				X: qzaxis[i],
+				Y: y,
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// calculates the reflectivity of all spin channels using 2x2 spinor transfer matrices
+//
+// inside a medium the sld is the matrix [[sld + m_par, m_perp], [m_perp, sld - m_par]] in spin space.
+// every slice transfers the spinor wave function and its derivative by [[cos(Kd), sin(Kd)/K], [-K sin(Kd), cos(Kd)]]
+// where K is the matrix of wave vectors, calculated from the eigenvalues sld ± |m|.
+// the ambient medium is non magnetic, roughness factors are not supported (use a microsliced profile)
+//
+// the result is ordered like the channel constants
+func CalculatePolarisedReflectivity(qzaxis []float64, stack *Stack) [][]float64 {
+	channels := make([][]float64, len(ChannelNames))
+	for c := range channels {
+		channels[c] = make([]float64, len(qzaxis))
+	}
+
+	nmedia := len(stack.SLD)
+	if nmedia < 2 {
+		return channels
+	}
+
+	ci := complex(0, 1.0)
+	identity := mat2{{1, 0}, {0, 1}}
+
+	for iq, q := range qzaxis {
+		k0 := q / 2.0
+
+		// total transfer matrix of all slices in 2x2 blocks, starting with the identity
+		m11, m12, m21, m22 := identity, mat2{}, mat2{}, identity
+
+		for j := 1; j < nmedia-1; j++ {
+			rotation, kPlus, kMinus := stack.spinWaveVectors(k0, j)
+			d := complex(stack.Thickness[j-1], 0)
+
+			// cos(kd), sin(kd)/k and k*sin(kd) for both eigenvalues, the limit k -> 0 is d and 0
+			var c, sk, ks [2]complex128
+			for e, k := range [2]complex128{kPlus, kMinus} {
+				c[e] = cmplx.Cos(k * d)
+				if k == 0 {
+					sk[e] = d
+				} else {
+					s := cmplx.Sin(k * d)
+					sk[e] = s / k
+					ks[e] = k * s
+				}
+			}
+			cosKd := rotation.diagonal(c[0], c[1])
+			sinKdK := rotation.diagonal(sk[0], sk[1])
+			kSinKd := rotation.diagonal(ks[0], ks[1])
+
+			// M = L_j * M
+			m11, m12, m21, m22 =
+				cosKd.mul(m11).add(sinKdK.mul(m21)),
+				cosKd.mul(m12).add(sinKdK.mul(m22)),
+				kSinKd.mul(m11).scale(-1).add(cosKd.mul(m21)),
+				kSinKd.mul(m12).scale(-1).add(cosKd.mul(m22))
+		}
+
+		kAmbient := waveVector(k0, 0)
+		rotation, kPlus, kMinus := stack.spinWaveVectors(k0, nmedia-1)
+		kSubstrate := rotation.diagonal(kPlus, kMinus)
+
+		// boundary conditions: (1+R, i k0 (1-R)) is transferred to (T, i K_s T)
+		x := kSubstrate.mul(m11).scale(ci).add(m21.scale(-1))
+		y := kSubstrate.mul(m12).scale(-kAmbient).add(m22.scale(-ci * kAmbient))
+
+		inverse, err := x.add(y.scale(-1)).inverse()
+		if err != nil {
+			for c := range channels {
+				channels[c][iq] = math.NaN()
+			}
+			continue
+		}
+		r := inverse.mul(x.add(y)).scale(-1)
+
+		// column: incident spin, row: reflected spin
+		channels[UP_UP][iq] = math.Pow(cmplx.Abs(r[0][0]), 2)
+		channels[DOWN_DOWN][iq] = math.Pow(cmplx.Abs(r[1][1]), 2)
+		channels[UP_DOWN][iq] = math.Pow(cmplx.Abs(r[1][0]), 2)
+		channels[DOWN_UP][iq] = math.Pow(cmplx.Abs(r[0][1]), 2)
+	}
+
+	return channels
+}
+
+// returns the eigenvectors (as rotation) and the wave vectors of the two spin eigenstates of medium i
+func (s *Stack) spinWaveVectors(k0 float64, i int) (rotation spinRotation, kPlus, kMinus complex128) {
+	var par, perp float64
+	if s.MagneticParallel != nil {
+		par = s.MagneticParallel[i]
+	}
+	if s.MagneticPerpendicular != nil {
+		perp = s.MagneticPerpendicular[i]
+	}
+
+	magnitude := complex(math.Hypot(par, perp), 0)
+	rotation = newSpinRotation(math.Atan2(perp, par) / 2)
+
+	kPlus = waveVector(k0, s.SLD[i]+magnitude-s.SLD[0])
+	kMinus = waveVector(k0, s.SLD[i]-magnitude-s.SLD[0])
+	return rotation, kPlus, kMinus
+}
+
+// mat2 is a complex 2x2 matrix in spin space
+type mat2 [2][2]complex128
+
+func (a mat2) mul(b mat2) mat2 {
+	return mat2{
+		{a[0][0]*b[0][0] + a[0][1]*b[1][0], a[0][0]*b[0][1] + a[0][1]*b[1][1]},
+		{a[1][0]*b[0][0] + a[1][1]*b[1][0], a[1][0]*b[0][1] + a[1][1]*b[1][1]},
+	}
+}
+
+func (a mat2) add(b mat2) mat2 {
+	return mat2{
+		{a[0][0] + b[0][0], a[0][1] + b[0][1]},
+		{a[1][0] + b[1][0], a[1][1] + b[1][1]},
+	}
+}
+
+func (a mat2) scale(f complex128) mat2 {
+	return mat2{
+		{f * a[0][0], f * a[0][1]},
+		{f * a[1][0], f * a[1][1]},
+	}
+}
+
+func (a mat2) inverse() (mat2, error) {
+	det := a[0][0]*a[1][1] - a[0][1]*a[1][0]
+	if det == 0 {
+		return mat2{}, errors.New("singular matrix")
+	}
+	return mat2{
+		{a[1][1] / det, -a[0][1] / det},
+		{-a[1][0] / det, a[0][0] / det},
+	}, nil
+}
+
+// spinRotation holds cos and sin of half the magnetisation angle, its columns are the spin eigenstates
+type spinRotation struct {
+	cos, sin complex128
+}
+
+func newSpinRotation(halfAngle float64) spinRotation {
+	return spinRotation{
+		cos: complex(math.Cos(halfAngle), 0),
+		sin: complex(math.Sin(halfAngle), 0),
+	}
+}
+
+// returns U diag(plus, minus) U^T
+func (u spinRotation) diagonal(plus, minus complex128) mat2 {
+	return mat2{
+		{u.cos*u.cos*plus + u.sin*u.sin*minus, u.cos * u.sin * (plus - minus)},
+		{u.cos * u.sin * (plus - minus), u.sin*u.sin*plus + u.cos*u.cos*minus},
+	}
+}
@@ -0,0 +1,118 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// helper creating a sliced magnetic stack of the test layer model with the same magnetisation in both layers
+func helperMagneticStack(t *testing.T, magnetic, angle float64, shift float64) *Stack {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zAxis := make([]float64, len(edenPoints))
+	for i, p := range edenPoints {
+		zAxis[i] = p.X
+	}
+
+	// the eden of the layers is shifted to compare collinear stacks with non magnetic ones
+	eden := make([]float64, len(testEden))
+	for i, e := range testEden {
+		eden[i] = e
+		if i > 0 && i < len(testEden)-1 {
+			eden[i] += shift
+		}
+	}
+	edenPoints, err = GetEdensities(eden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stack, err := NewProfileStack(zAxis, sld)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parallel, perpendicular, err := GetMagneticProfiles(zAxis, []float64{magnetic, magnetic, 0}, []float64{angle, angle, 0}, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack.MagneticParallel = make([]float64, len(zAxis))
+	stack.MagneticPerpendicular = make([]float64, len(zAxis))
+	for i := range zAxis {
+		stack.MagneticParallel[i] = parallel[i].Y * ELECTRON_RADIUS
+		stack.MagneticPerpendicular[i] = perpendicular[i].Y * ELECTRON_RADIUS
+	}
+
+	return stack
+}
+
+func helperAssertEqual(t *testing.T, name string, qz, a, b []float64) {
+	for i := range qz {
+		if math.Abs(a[i]-b[i]) > 1e-7*math.Max(math.Abs(b[i]), 1e-12) {
+			t.Errorf("%s differs at q=%f: %g vs %g", name, qz[i], a[i], b[i])
+			return
+		}
+	}
+}
+
+// without magnetisation both non spin flip channels are the scalar reflectivity and there is no spin flip
+func TestPolarisedNonMagnetic(t *testing.T) {
+	stack := helperMagneticStack(t, 0, 0, 0)
+	qz := helperSyntheticQz(t)
+
+	channels := CalculatePolarisedReflectivity(qz, stack)
+	scalar := CalculateParrattReflectivity(qz, stack)
+
+	helperAssertEqual(t, "++", qz, channels[UP_UP], scalar)
+	helperAssertEqual(t, "--", qz, channels[DOWN_DOWN], scalar)
+	for i := range qz {
+		if channels[UP_DOWN][i] != 0 || channels[DOWN_UP][i] != 0 {
+			t.Fatalf("spin flip without magnetisation at q=%f", qz[i])
+		}
+	}
+}
+
+// a magnetisation parallel to the polarisation shifts the sld by ± the magnetic sld without spin flip
+func TestPolarisedCollinear(t *testing.T) {
+	qz := helperSyntheticQz(t)
+	channels := CalculatePolarisedReflectivity(qz, helperMagneticStack(t, 0.05, 0, 0))
+
+	up := helperMagneticStack(t, 0, 0, 0.05)
+	down := helperMagneticStack(t, 0, 0, -0.05)
+	helperAssertEqual(t, "++", qz, channels[UP_UP], CalculateParrattReflectivity(qz, up))
+	helperAssertEqual(t, "--", qz, channels[DOWN_DOWN], CalculateParrattReflectivity(qz, down))
+
+	// an antiparallel magnetisation swaps the channels
+	antiparallel := CalculatePolarisedReflectivity(qz, helperMagneticStack(t, 0.05, 180, 0))
+	helperAssertEqual(t, "++/--", qz, antiparallel[UP_UP], channels[DOWN_DOWN])
+	helperAssertEqual(t, "--/++", qz, antiparallel[DOWN_DOWN], channels[UP_UP])
+}
+
+// a perpendicular magnetisation causes spin flip, both spin flip channels are equal
+func TestPolarisedPerpendicular(t *testing.T) {
+	qz := helperSyntheticQz(t)
+	channels := CalculatePolarisedReflectivity(qz, helperMagneticStack(t, 0.05, 90, 0))
+
+	helperAssertEqual(t, "++/--", qz, channels[UP_UP], channels[DOWN_DOWN])
+	helperAssertEqual(t, "+-/-+", qz, channels[UP_DOWN], channels[DOWN_UP])
+
+	spinFlip := 0.0
+	for i := range qz {
+		spinFlip = max(spinFlip, channels[UP_DOWN][i])
+
+		// no absorption: nothing can get lost below the critical edge
+		if total := channels[UP_UP][i] + channels[UP_DOWN][i]; total > 1+1e-9 {
+			t.Errorf("reflectivity larger than 1 at q=%f: %g", qz[i], total)
+		}
+	}
+	if spinFlip == 0 {
+		t.Error("expected spin flip for a perpendicular magnetisation")
+	}
+}
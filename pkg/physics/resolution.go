@@ -0,0 +1,114 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+)
+
+const (
+	// number of sampling points used for the gaussian resolution convolution
+	RESOLUTION_POINTS = 17
+	// range of the gaussian resolution convolution in units of its standard deviation
+	RESOLUTION_RANGE = 3.5
+)
+
+// Resolution describes the instrumental resolution as a gaussian in q
+type Resolution struct {
+	// constant relative resolution dQ/Q (standard deviation of the gaussian divided by q)
+	Relative float64
+
+	// optional data points with an absolute resolution dQ (Point.Resolution),
+	// for q values of these points the absolute resolution is used instead of the relative one.
+	// The points are matched by their q value, so data tracks sharing q values with a different
+	// resolution need a Resolution each
+	Points function.Points
+}
+
+// Widths returns the standard deviation of the resolution for every q value
+func (r *Resolution) Widths(qzaxis []float64) []float64 {
+	widths := make([]float64, len(qzaxis))
+	if r == nil {
+		return widths
+	}
+
+	// collect per point resolutions
+	perPoint := make(map[float64]float64)
+	for _, p := range r.Points {
+		if p.Resolution > 0 {
+			perPoint[p.X] = p.Resolution
+		}
+	}
+
+	for i, q := range qzaxis {
+		if w, ok := perPoint[q]; ok {
+			widths[i] = w
+		} else {
+			widths[i] = math.Abs(r.Relative * q)
+		}
+	}
+
+	return widths
+}
+
+// CalculateSmearedReflectivity calculates the reflectivity convoluted with a gaussian resolution
+//
+// engine: reflectivity engine used for the sampling points
+//
+// widths: standard deviation of the resolution for every q value, 0 disables the convolution for this value
+func CalculateSmearedReflectivity(engine ReflectivityEngine, qzaxis []float64, widths []float64, stack *Stack) []float64 {
+	return smearChannels(qzaxis, widths, func(sampled []float64) [][]float64 {
+		return [][]float64{engine.Reflectivity(sampled, stack)}
+	})[0]
+}
+
+// smearChannels convolutes one or more reflectivity channels with a gaussian resolution
+//
+// reflectivity is called once with all sampling points and returns the reflectivity of every channel
+func smearChannels(qzaxis []float64, widths []float64, reflectivity func(sampled []float64) [][]float64) [][]float64 {
+	// gaussian weights at equidistant sampling points
+	offsets := make([]float64, RESOLUTION_POINTS)
+	weights := make([]float64, RESOLUTION_POINTS)
+	var weightSum float64
+	for k := range offsets {
+		offsets[k] = -RESOLUTION_RANGE + 2*RESOLUTION_RANGE*float64(k)/float64(RESOLUTION_POINTS-1)
+		weights[k] = math.Exp(-offsets[k] * offsets[k] / 2)
+		weightSum += weights[k]
+	}
+	for k := range weights {
+		weights[k] /= weightSum
+	}
+
+	// collect all q values so the reflectivity is calculated in a single call
+	sampled := make([]float64, 0, len(qzaxis)*RESOLUTION_POINTS)
+	for i, q := range qzaxis {
+		if widths[i] <= 0 {
+			sampled = append(sampled, q)
+			continue
+		}
+		for _, o := range offsets {
+			sampled = append(sampled, q+o*widths[i])
+		}
+	}
+	sampledChannels := reflectivity(sampled)
+
+	// weighted sum over the sampling points
+	channels := make([][]float64, len(sampledChannels))
+	for c, sampledRefl := range sampledChannels {
+		refl := make([]float64, len(qzaxis))
+		idx := 0
+		for i := range qzaxis {
+			if widths[i] <= 0 {
+				refl[i] = sampledRefl[idx]
+				idx++
+				continue
+			}
+			for k := range weights {
+				refl[i] += weights[k] * sampledRefl[idx]
+				idx++
+			}
+		}
+		channels[c] = refl
+	}
+
+	return channels
+}
@@ -0,0 +1,100 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"testing"
+)
+
+func TestResolutionWidths(t *testing.T) {
+	res := &Resolution{
+		Relative: 0.05,
+		Points: function.Points{
+			{X: 0.1, Y: 1, Error: 0.1, Resolution: 0.002},
+			{X: 0.2, Y: 1, Error: 0.1},
+		},
+	}
+
+	widths := res.Widths([]float64{0.1, 0.2, -0.3})
+	expected := []float64{0.002, 0.01, 0.015}
+	for i := range expected {
+		if math.Abs(widths[i]-expected[i]) > 1e-12 {
+			t.Errorf("expected width %g got %g", expected[i], widths[i])
+		}
+	}
+
+	// no resolution leads to zero widths
+	var none *Resolution
+	for _, w := range none.Widths([]float64{0.1, 0.2}) {
+		if w != 0 {
+			t.Errorf("expected zero width got %g", w)
+		}
+	}
+}
+
+// the convolution has to keep the reflectivity for zero widths and smooth the fringe minima otherwise
+func TestSmearedReflectivity(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltaz := edenPoints[1].X - edenPoints[0].X
+
+	qz := make([]float64, 400)
+	for i := range qz {
+		qz[i] = 0.05 + float64(i)*0.001
+	}
+
+	ideal := CalculateComplexReflectivity(qz, deltaz, sld)
+	unchanged := CalculateSmearedReflectivity(ParrattEngine{}, qz, make([]float64, len(qz)), NewSlicedStack(deltaz, sld))
+	smeared := CalculateSmearedReflectivity(ParrattEngine{}, qz, (&Resolution{Relative: 0.05}).Widths(qz), NewSlicedStack(deltaz, sld))
+
+	minIdeal, minSmeared := math.MaxFloat64, math.MaxFloat64
+	for i := range qz {
+		if ideal[i] != unchanged[i] {
+			t.Errorf("expected unchanged reflectivity at q=%f: %g vs %g", qz[i], ideal[i], unchanged[i])
+		}
+		minIdeal = min(minIdeal, ideal[i])
+		minSmeared = min(minSmeared, smeared[i])
+	}
+
+	if minSmeared <= minIdeal {
+		t.Errorf("expected the resolution to fill the fringe minimum: %g vs %g", minSmeared, minIdeal)
+	}
+}
+
+// two data tracks measured at the same q with a different resolution get the intensity of their own resolution
+func TestResolutionSharedQ(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fine := function.Points{{X: 0.1, Y: 1, Error: 0.1, Resolution: 0.0005}, {X: 0.15, Y: 1, Error: 0.1, Resolution: 0.0005}}
+	coarse := function.Points{{X: 0.1, Y: 1, Error: 0.1, Resolution: 0.01}, {X: 0.12, Y: 1, Error: 0.1, Resolution: 0.01}}
+
+	intensities := make([]float64, 0)
+	for _, track := range []function.Points{fine, coarse} {
+		qz := GetDataQZAxis([]function.Points{track})
+		res := &Resolution{Relative: 0.05, Points: track}
+		for i, w := range res.Widths(qz) {
+			if w != track[i].Resolution {
+				t.Errorf("expected the width %g of the track at q=%f got %g", track[i].Resolution, qz[i], w)
+			}
+		}
+
+		intensity, err := CalculateIntensityPoints(edenPoints, qz, 0, &IntensityOptions{Scaling: 1, Resolution: res})
+		if err != nil {
+			t.Fatal(err)
+		}
+		intensities = append(intensities, intensity[0].Y)
+	}
+
+	if math.Abs(intensities[0]-intensities[1]) < 1e-3*intensities[0] {
+		t.Errorf("expected different intensities at the shared q for different resolutions: %g vs %g", intensities[0], intensities[1])
+	}
+}
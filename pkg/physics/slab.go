@@ -0,0 +1,131 @@
+package physics
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// RoughnessModel selects the factor which damps the fresnel coefficient of a rough interface
+type RoughnessModel string
+
+const (
+	// r_ij * exp(-2 k_i k_j sigma^2)
+	NEVOT_CROCE RoughnessModel = "Névot-Croce"
+	// r_ij * exp(-2 k_i^2 sigma^2)
+	DEBYE_WALLER RoughnessModel = "Debye-Waller"
+)
+
+// RoughnessModelNames returns the names of all roughness models
+func RoughnessModelNames() []string {
+	return []string{string(NEVOT_CROCE), string(DEBYE_WALLER)}
+}
+
+// Stack describes the sample by media with sharp interfaces
+//
+// a microsliced profile is a stack with equal thicknesses and without roughness (see NewSlicedStack),
+// a slab model uses the layers directly and describes the roughness by damping factors (see NewSlabStack)
+type Stack struct {
+	// complex slds {sld_a,sld_1,...,sld_n,sld_b} of ambient medium, layers and substrate
+	SLD []complex128
+
+	// thickness {d_1,...,d_n} of every layer
+	Thickness []float64
+
+	// optional roughness {sigma_a1,...,sigma_nb} of every interface, nil for sharp interfaces
+	Roughness []float64
+
+	// model of the roughness factors, the default is NEVOT_CROCE
+	RoughnessModel RoughnessModel
+
+	// optional magnetic sld components parallel and perpendicular to the polarisation axis of every medium,
+	// nil for non magnetic stacks, only used by CalculatePolarisedReflectivity
+	MagneticParallel      []float64
+	MagneticPerpendicular []float64
+}
+
+// NewSlicedStack creates a stack of slices with the thickness deltaz from a microsliced sld profile
+func NewSlicedStack(deltaz float64, sld []complex128) *Stack {
+	thickness := make([]float64, max(len(sld)-2, 0))
+	for i := range thickness {
+		thickness[i] = deltaz
+	}
+
+	return &Stack{
+		SLD:       sld,
+		Thickness: thickness,
+	}
+}
+
+// NewProfileStack creates a stack from an sld profile sampled at the z values of zaxis
+// every slice reaches halfway to its neighbouring z values, so the z axis does not need to be equidistant
+func NewProfileStack(zaxis []float64, sld []complex128) (*Stack, error) {
+	if len(zaxis) != len(sld) {
+		return nil, fmt.Errorf("z axis and sld profile have different lengths: %d vs %d", len(zaxis), len(sld))
+	}
+
+	thickness := make([]float64, max(len(sld)-2, 0))
+	for i := range thickness {
+		thickness[i] = (zaxis[i+2] - zaxis[i]) / 2
+	}
+
+	return &Stack{
+		SLD:       sld,
+		Thickness: thickness,
+	}, nil
+}
+
+// NewSlabStack creates a slab model from the layer parameters
+// - eden, d and sigma are the same as for GetEdensities
+// - absorption is the same as for GetAbsorptions, nil for non absorbing media
+func NewSlabStack(eden, d, sigma, absorption []float64, model RoughnessModel) (*Stack, error) {
+	if len(eden) != len(d)+2 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality edensities %d/thickness %d", len(eden), len(d))
+	}
+	if len(sigma) != len(d)+1 {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality roughness %d/thickness %d", len(sigma), len(d))
+	}
+	if absorption != nil && len(absorption) != len(eden) {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality absorptions %d/thickness %d", len(absorption), len(d))
+	}
+
+	sld := make([]complex128, len(eden))
+	for i, e := range eden {
+		a := 0.0
+		if absorption != nil {
+			a = absorption[i]
+		}
+		sld[i] = complex(e*ELECTRON_RADIUS, -a*ELECTRON_RADIUS)
+	}
+
+	return &Stack{
+		SLD:            sld,
+		Thickness:      d,
+		Roughness:      sigma,
+		RoughnessModel: model,
+	}, nil
+}
+
+// returns the wave vectors of all media for the wave vector k0 in the ambient medium
+func (s *Stack) waveVectors(k0 float64) []complex128 {
+	k := make([]complex128, len(s.SLD))
+	for i := range s.SLD {
+		k[i] = waveVector(k0, s.SLD[i]-s.SLD[0])
+	}
+	return k
+}
+
+// returns the fresnel coefficient of interface i (between medium i and i+1) including its roughness factor
+func (s *Stack) fresnel(k []complex128, i int) complex128 {
+	r := (k[i] - k[i+1]) / (k[i] + k[i+1])
+	if s.Roughness == nil || s.Roughness[i] == 0 {
+		return r
+	}
+
+	sigma2 := complex(s.Roughness[i]*s.Roughness[i], 0)
+	switch s.RoughnessModel {
+	case DEBYE_WALLER:
+		return r * cmplx.Exp(-2*k[i]*k[i]*sigma2)
+	default:
+		return r * cmplx.Exp(-2*k[i]*k[i+1]*sigma2)
+	}
+}
@@ -0,0 +1,92 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// a slab model without roughness is the same as a microsliced profile of sharp steps
+func TestSlabMatchesSharpSlices(t *testing.T) {
+	eden := []float64{0.0, 0.35, 0.46, 0.334}
+	d := []float64{10, 20}
+
+	slab, err := NewSlabStack(eden, d, []float64{0, 0, 0}, nil, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// slices with a thickness of 1
+	sld := []complex128{slab.SLD[0]}
+	for i, thickness := range d {
+		for range int(thickness) {
+			sld = append(sld, slab.SLD[i+1])
+		}
+	}
+	sld = append(sld, slab.SLD[3])
+
+	qz := GetDefaultQZAxis(500)
+	sliced := CalculateParrattReflectivity(qz, NewSlicedStack(1, sld))
+	for _, engine := range engines {
+		refl := engine.Reflectivity(qz, slab)
+		for i := range qz {
+			if math.Abs(refl[i]-sliced[i]) > 1e-9*sliced[i] {
+				t.Errorf("%s: reflectivity differs at q=%f: %g vs %g", engine.Name(), qz[i], refl[i], sliced[i])
+			}
+		}
+	}
+}
+
+// both engines need to apply the roughness factors the same way
+func TestSlabEnginesAgree(t *testing.T) {
+	for _, model := range []RoughnessModel{NEVOT_CROCE, DEBYE_WALLER} {
+		slab, err := NewSlabStack(testEden, testD, testSigma, []float64{0, 0.005, 0.001, 0.01}, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		qz := GetDefaultQZAxis(500)
+		parratt := ParrattEngine{}.Reflectivity(qz, slab)
+		abeles := AbelesEngine{}.Reflectivity(qz, slab)
+		for i := range qz {
+			if math.Abs(parratt[i]-abeles[i]) > 1e-9*parratt[i] {
+				t.Errorf("%s: engines differ at q=%f: %g vs %g", model, qz[i], parratt[i], abeles[i])
+			}
+		}
+	}
+}
+
+// the Névot-Croce slab model approximates the microsliced erf profile
+func TestSlabApproximatesProfile(t *testing.T) {
+	edenPoints, err := GetEdensities(testEden, testD, testSigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sld, err := GetSLDs(edenPoints, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slab, err := NewSlabStack(testEden, testD, testSigma, nil, NEVOT_CROCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qz := helperSyntheticQz(t)
+	sliced := CalculateParrattReflectivity(qz, NewSlicedStack(edenPoints[1].X-edenPoints[0].X, sld))
+	refl := CalculateParrattReflectivity(qz, slab)
+	maxDiff := 0.0
+	for i := range qz {
+		maxDiff = max(maxDiff, math.Abs(refl[i]-sliced[i])/sliced[i])
+	}
+	if maxDiff > 0.02 {
+		t.Errorf("slab model differs from the microsliced profile by %.2f%%", 100*maxDiff)
+	}
+}
+
+func TestSlabDimensionMismatch(t *testing.T) {
+	if _, err := NewSlabStack(testEden, testD[:1], testSigma, nil, NEVOT_CROCE); err == nil {
+		t.Error("expected error for wrong number of thicknesses")
+	}
+	if _, err := NewSlabStack(testEden, testD, testSigma, []float64{0}, NEVOT_CROCE); err == nil {
+		t.Error("expected error for wrong number of absorptions")
+	}
+}
@@ -0,0 +1,251 @@
+package physics
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+	"slices"
+	"strconv"
+)
+
+// parameter group generated by a spline profile
+const SPLINE_GROUP = "spline"
+
+const (
+	// distance between the knots of newly created spline profiles in angstrom
+	DEFAULT_KNOT_SPACING = 10.0
+	// range before the first and after the last knot which is part of the profile
+	SPLINE_MARGIN = 20.0
+	// number of knots needed to interpolate a profile
+	MIN_SPLINE_KNOTS = 2
+)
+
+// SplineInterpolation is the interpolation between the knots of a spline profile
+type SplineInterpolation string
+
+const (
+	// natural cubic spline, smooth second derivative, can overshoot between the knots
+	NATURAL_SPLINE = SplineInterpolation("Natural")
+	// monotone cubic hermite spline (Fritsch-Carlson), no overshoot between the knots
+	MONOTONE_SPLINE = SplineInterpolation("Monotone")
+)
+
+// SplineInterpolationNames returns the names of all spline interpolations
+func SplineInterpolationNames() []string {
+	return []string{string(NATURAL_SPLINE), string(MONOTONE_SPLINE)}
+}
+
+// SplineProfile describes a model independent profile by knots (z, eden, absorption) interpolated by a cubic spline
+//
+// the profile is constant before the first knot (ambient medium) and after the last knot (substrate),
+// the knots are sorted by their position, so fitted positions may pass each other
+type SplineProfile struct {
+	Knots int
+}
+
+// SplineValues holds the parameter values of a spline profile split into the single groups
+type SplineValues struct {
+	Z          []float64
+	Eden       []float64
+	Absorption []float64
+}
+
+// creates a new spline profile with n knots, at least MIN_SPLINE_KNOTS
+func NewSplineProfile(knots int) *SplineProfile {
+	return &SplineProfile{
+		Knots: max(knots, MIN_SPLINE_KNOTS),
+	}
+}
+
+// returns all parameters of the profile in the order expected by Split
+//
+// order: position {1,...,n}, eden {1,...,n}, absorption {1,...,n}
+func (s *SplineProfile) Parameters() []ParameterSpec {
+	specs := make([]ParameterSpec, 0, s.ParameterCount())
+
+	for i := 1; i <= s.Knots; i++ {
+		specs = append(specs, ParameterSpec{SPLINE_GROUP, "Knot z " + strconv.Itoa(i), float64(i-1) * DEFAULT_KNOT_SPACING})
+	}
+	for i := 1; i <= s.Knots; i++ {
+		specs = append(specs, ParameterSpec{SPLINE_GROUP, "Knot Eden " + strconv.Itoa(i), DEFAULT_EDEN})
+	}
+	for i := 1; i <= s.Knots; i++ {
+		specs = append(specs, ParameterSpec{SPLINE_GROUP, "Knot Absorption " + strconv.Itoa(i), DEFAULT_ABSORPTION})
+	}
+
+	return specs
+}
+
+// returns the number of parameters of the profile
+func (s *SplineProfile) ParameterCount() int {
+	return 3 * s.Knots
+}
+
+// splits the parameter values (ordered like Parameters) into the single groups
+func (s *SplineProfile) Split(params []float64) (*SplineValues, error) {
+	if len(params) != s.ParameterCount() {
+		return nil, fmt.Errorf("spline profile with %d knots expects %d parameters but got %d", s.Knots, s.ParameterCount(), len(params))
+	}
+
+	n := s.Knots
+	return &SplineValues{
+		Z:          params[0:n],
+		Eden:       params[n : 2*n],
+		Absorption: params[2*n : 3*n],
+	}, nil
+}
+
+// GetSplineZAxis returns an equidistant z axis covering the knots and SPLINE_MARGIN before and after them
+// - z are the positions of the knots
+// - maxSlice is the maximum distance between two z values
+func GetSplineZAxis(z []float64, maxSlice float64) ([]float64, error) {
+	if len(z) == 0 {
+		return nil, fmt.Errorf("spline profile needs knots")
+	}
+	if !(maxSlice > 0) {
+		return nil, fmt.Errorf("maximum slice thickness needs to be positive: %f", maxSlice)
+	}
+
+	start := slices.Min(z) - SPLINE_MARGIN
+	end := slices.Max(z) + SPLINE_MARGIN
+	n := int(math.Ceil((end-start)/maxSlice)) + 1
+	if n > MAX_SLICES {
+		return nil, fmt.Errorf("spline z axis needs more than %d slices", MAX_SLICES)
+	}
+
+	zAxis := make([]float64, n)
+	for i := range zAxis {
+		zAxis[i] = start + float64(i)*(end-start)/float64(n-1)
+	}
+	return zAxis, nil
+}
+
+// GetSplineProfile returns the profile interpolating the knots (z, values) at the given z values
+func GetSplineProfile(zaxis []float64, z []float64, values []float64, interpolation SplineInterpolation) (function.Points, error) {
+	if len(z) != len(values) {
+		return nil, fmt.Errorf("missmatch in parameter dimensionality knot positions %d/values %d", len(z), len(values))
+	}
+	if len(z) < MIN_SPLINE_KNOTS {
+		return nil, fmt.Errorf("spline profile needs at least %d knots but got %d", MIN_SPLINE_KNOTS, len(z))
+	}
+
+	// sort the knots by position
+	order := make([]int, len(z))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Compare(z[a], z[b])
+	})
+	x := make([]float64, len(z))
+	y := make([]float64, len(z))
+	for i, o := range order {
+		x[i], y[i] = z[o], values[o]
+		if i > 0 && x[i] == x[i-1] {
+			return nil, fmt.Errorf("spline knots need distinct positions: %f", x[i])
+		}
+	}
+
+	var slopes []float64
+	switch interpolation {
+	case NATURAL_SPLINE:
+		slopes = naturalSplineSlopes(x, y)
+	case MONOTONE_SPLINE:
+		slopes = monotoneSplineSlopes(x, y)
+	default:
+		return nil, fmt.Errorf("unknown spline interpolation '%s'", interpolation)
+	}
+
+	profile := make(function.Points, len(zaxis))
+	for i, zi := range zaxis {
+		profile[i] = &function.Point{
+			X: zi,
+			Y: evalHermite(x, y, slopes, zi),
+		}
+	}
+	return profile, nil
+}
+
+// returns the slopes of the natural cubic spline (second derivative 0 at the first and last knot)
+// the tridiagonal system for the slopes is solved with the thomas algorithm
+func naturalSplineSlopes(x, y []float64) []float64 {
+	n := len(x)
+	h := make([]float64, n-1)
+	delta := make([]float64, n-1)
+	for i := range h {
+		h[i] = x[i+1] - x[i]
+		delta[i] = (y[i+1] - y[i]) / h[i]
+	}
+
+	// lower, diagonal and upper band and right hand side
+	a := make([]float64, n)
+	b := make([]float64, n)
+	c := make([]float64, n)
+	r := make([]float64, n)
+
+	b[0], c[0], r[0] = 2, 1, 3*delta[0]
+	for i := 1; i < n-1; i++ {
+		a[i] = h[i]
+		b[i] = 2 * (h[i-1] + h[i])
+		c[i] = h[i-1]
+		r[i] = 3 * (h[i]*delta[i-1] + h[i-1]*delta[i])
+	}
+	a[n-1], b[n-1], r[n-1] = 1, 2, 3*delta[n-2]
+
+	for i := 1; i < n; i++ {
+		w := a[i] / b[i-1]
+		b[i] -= w * c[i-1]
+		r[i] -= w * r[i-1]
+	}
+	slopes := make([]float64, n)
+	slopes[n-1] = r[n-1] / b[n-1]
+	for i := n - 2; i >= 0; i-- {
+		slopes[i] = (r[i] - c[i]*slopes[i+1]) / b[i]
+	}
+	return slopes
+}
+
+// returns the slopes of the monotone cubic hermite spline (Fritsch-Carlson)
+func monotoneSplineSlopes(x, y []float64) []float64 {
+	n := len(x)
+	h := make([]float64, n-1)
+	delta := make([]float64, n-1)
+	for i := range h {
+		h[i] = x[i+1] - x[i]
+		delta[i] = (y[i+1] - y[i]) / h[i]
+	}
+
+	// the first and last knot use the secant of their interval
+	slopes := make([]float64, n)
+	slopes[0] = delta[0]
+	slopes[n-1] = delta[n-2]
+	for i := 1; i < n-1; i++ {
+		// weighted harmonic mean of the secants, 0 at extrema
+		if delta[i-1]*delta[i] > 0 {
+			w1 := 2*h[i] + h[i-1]
+			w2 := h[i] + 2*h[i-1]
+			slopes[i] = (w1 + w2) / (w1/delta[i-1] + w2/delta[i])
+		}
+	}
+	return slopes
+}
+
+// evaluates the cubic hermite interpolation of the knots with the given slopes, constant outside the knots
+func evalHermite(x, y, slopes []float64, z float64) float64 {
+	n := len(x)
+	if z <= x[0] {
+		return y[0]
+	}
+	if z >= x[n-1] {
+		return y[n-1]
+	}
+
+	i, _ := slices.BinarySearch(x, z)
+	i = max(i-1, 0)
+	h := x[i+1] - x[i]
+	t := (z - x[i]) / h
+	t2, t3 := t*t, t*t*t
+
+	return (2*t3-3*t2+1)*y[i] + (t3-2*t2+t)*h*slopes[i] + (-2*t3+3*t2)*y[i+1] + (t3-t2)*h*slopes[i+1]
+}
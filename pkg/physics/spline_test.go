@@ -0,0 +1,69 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// both interpolations hit the knots and reproduce a straight line
+func TestSplineProfileKnots(t *testing.T) {
+	z := []float64{20, 0, 10, 35}
+	values := []float64{2, 0, 1, 3.5}
+
+	zaxis, err := GetSplineZAxis(z, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zaxis[0] != -SPLINE_MARGIN || zaxis[len(zaxis)-1] != 35+SPLINE_MARGIN {
+		t.Errorf("wrong z axis range %f to %f", zaxis[0], zaxis[len(zaxis)-1])
+	}
+
+	for _, interpolation := range SplineInterpolationNames() {
+		profile, err := GetSplineProfile(zaxis, z, values, SplineInterpolation(interpolation))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range profile {
+			expected := math.Min(math.Max(p.X/10, 0), 3.5)
+			if math.Abs(p.Y-expected) > 1e-9 {
+				t.Errorf("%s: expected %f at z=%f got %f", interpolation, expected, p.X, p.Y)
+			}
+		}
+	}
+}
+
+// the monotone spline does not overshoot a step, the natural spline does
+func TestSplineProfileMonotone(t *testing.T) {
+	z := []float64{0, 10, 20, 30}
+	values := []float64{0, 0, 1, 1}
+	zaxis, err := GetSplineZAxis(z, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monotone, err := GetSplineProfile(zaxis, z, values, MONOTONE_SPLINE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(monotone); i++ {
+		if monotone[i].Y < monotone[i-1].Y || monotone[i].Y > 1 {
+			t.Errorf("monotone spline overshoots at z=%f: %f", monotone[i].X, monotone[i].Y)
+		}
+	}
+
+	natural, err := GetSplineProfile(zaxis, z, values, NATURAL_SPLINE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overshoot := false
+	for _, p := range natural {
+		overshoot = overshoot || p.Y < -1e-3 || p.Y > 1+1e-3
+	}
+	if !overshoot {
+		t.Error("expected the natural spline to overshoot the step")
+	}
+
+	if _, err := GetSplineProfile(zaxis, []float64{0, 0}, []float64{0, 1}, NATURAL_SPLINE); err == nil {
+		t.Error("expected an error for knots at the same position")
+	}
+}